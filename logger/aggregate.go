@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// WithAggregation buffers the entries of the given levels and emits one summary per window
+// for each distinct message and source location, annotated with the number of occurrences
+// in the "aggregation" group. The summary carries the attributes of the first occurrence.
+// Entries at LevelError or above are never aggregated.
+func WithAggregation(window time.Duration, levels []slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.aggregator = &aggregator{
+			window: window,
+			levels: slices.Clone(levels),
+			emit:   l.emit,
+		}
+	}
+}
+
+type aggregateKey struct {
+	level slog.Level
+	msg   string
+	pc    uintptr
+}
+
+type aggregateBucket struct {
+	record slog.Record
+	count  int
+}
+
+type aggregator struct {
+	window time.Duration
+	levels []slog.Level
+	emit   func(context.Context, slog.Record)
+
+	mu      sync.Mutex
+	order   []aggregateKey
+	buckets map[aggregateKey]*aggregateBucket
+}
+
+// add buffers the record and reports whether it has been taken by the aggregator.
+func (a *aggregator) add(r slog.Record) bool {
+	if r.Level >= LevelError || !slices.Contains(a.levels, r.Level) {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.buckets == nil {
+		a.buckets = make(map[aggregateKey]*aggregateBucket)
+		time.AfterFunc(a.window, a.flush)
+	}
+	key := aggregateKey{level: r.Level, msg: r.Message, pc: r.PC}
+	if b, ok := a.buckets[key]; ok {
+		b.count++
+		return true
+	}
+	a.order = append(a.order, key)
+	a.buckets[key] = &aggregateBucket{record: r.Clone(), count: 1}
+	return true
+}
+
+// flush emits the summaries of the current window.
+func (a *aggregator) flush() {
+	a.mu.Lock()
+	order, buckets := a.order, a.buckets
+	a.order, a.buckets = nil, nil
+	a.mu.Unlock()
+
+	for _, key := range order {
+		b := buckets[key]
+		b.record.AddAttrs(slog.Group("aggregation",
+			slog.Int("count", b.count),
+			slog.String("window", a.window.String()),
+		))
+		a.emit(context.Background(), b.record)
+	}
+}
@@ -0,0 +1,87 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestAggregationWindow(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithAggregation(200*time.Millisecond, []slog.Level{logger.LevelInfo}))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		l.Info(ctx, "hello", logger.WithAttrs(slog.Int("i", i)))
+	}
+	l.Info(ctx, "other")
+	if got := len(capture.Entries()); got != 0 {
+		t.Fatalf("got %d entries in the window, want none", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(capture.Entries()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("the summaries are not emitted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want a summary for each message", len(entries))
+	}
+	for i, want := range []struct {
+		msg   string
+		count float64
+	}{{"hello", 3}, {"other", 1}} {
+		e := entries[i]
+		aggregation, _ := e.Attrs["aggregation"].(map[string]any)
+		if e.Message != want.msg || aggregation["count"] != want.count || aggregation["window"] != "200ms" {
+			t.Errorf("entries[%d] = %+v, want the summary of %d %q", i, e, int(want.count), want.msg)
+		}
+	}
+	if entries[0].Attrs["i"] != float64(0) {
+		t.Errorf("i = %v, want the attributes of the first occurrence", entries[0].Attrs["i"])
+	}
+}
+
+func TestAggregationBypass(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithAggregation(time.Hour, []slog.Level{logger.LevelInfo, logger.LevelError}))
+	ctx := context.Background()
+
+	l.Error(ctx, errors.New("failed"))
+	l.Error(ctx, errors.New("failed"))
+	l.Warn(ctx, "not in the levels")
+	entries := capture.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want the errors and the warning written at once", len(entries))
+	}
+	for _, e := range entries {
+		if _, ok := e.Attrs["aggregation"]; ok {
+			t.Errorf("%q is aggregated", e.Message)
+		}
+	}
+}
+
+func TestAggregationClose(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithAggregation(time.Hour, []slog.Level{logger.LevelDebug}))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		l.Debug(ctx, "pending")
+	}
+	if err := l.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want the summary flushed by Close", len(entries))
+	}
+	if aggregation, _ := entries[0].Attrs["aggregation"].(map[string]any); aggregation["count"] != float64(2) {
+		t.Errorf("aggregation = %v, want the count of 2", aggregation)
+	}
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+)
+
+// ansiPattern matches CSI sequences (e.g. colors), OSC sequences (e.g. hyperlinks) and
+// the other two-byte escape sequences.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// WithStripANSI removes ANSI escape sequences from the message and the string attributes.
+// It is useful when the message comes from a subprocess or a library which colors its output.
+func WithStripANSI() LoggerOption {
+	return func(l *Logger) {
+		l.stripANSI = true
+	}
+}
+
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+func stripANSIAttrs(attrs []slog.Attr) []slog.Attr {
+	stripped := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		stripped[i] = stripANSIAttr(a)
+	}
+	return stripped
+}
+
+func stripANSIAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, stripANSI(v.String()))
+	case slog.KindGroup:
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(stripANSIAttrs(v.Group())...)}
+	default:
+		return slog.Attr{Key: a.Key, Value: v}
+	}
+}
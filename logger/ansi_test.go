@@ -0,0 +1,49 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestStripANSI(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithStripANSI())
+
+	l.Info(context.Background(), "\x1b[31mfailed\x1b[0m to \x1b]8;;https://example.com\x07connect\x1b]8;;\x07",
+		logger.WithAttrs(
+			slog.String("output", "\x1b[1;32mok\x1b[m"),
+			slog.Group("cmd", slog.String("stderr", "\x1b[33mwarning\x1b[0m")),
+			slog.Int("code", 1),
+		))
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "failed to connect" {
+		t.Errorf("message = %q, want the one without escape sequences", e.Message)
+	}
+	if e.Attrs["output"] != "ok" {
+		t.Errorf("output = %q, want the one without escape sequences", e.Attrs["output"])
+	}
+	if cmd, _ := e.Attrs["cmd"].(map[string]any); cmd["stderr"] != "warning" {
+		t.Errorf("cmd = %v, want the one without escape sequences", e.Attrs["cmd"])
+	}
+	if e.Attrs["code"] != float64(1) {
+		t.Errorf("code = %v, want 1", e.Attrs["code"])
+	}
+}
+
+func TestWithoutStripANSI(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	l.Info(context.Background(), "\x1b[31mfailed\x1b[0m")
+
+	if entries := capture.Entries(); len(entries) != 1 || entries[0].Message != "\x1b[31mfailed\x1b[0m" {
+		t.Errorf("entries = %+v, want the message as it is", entries)
+	}
+}
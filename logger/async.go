@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// WithAsync makes the logger hand the entries over to a background goroutine,
+// so that the caller does not wait for the handler to write them.
+//
+// The entries are written in the order they are logged. When the buffer of bufferSize entries is full,
+// the entry is dropped rather than blocking the caller: the callback set by WithDroppedEntryCallback
+// is called with DropReasonBufferFull, and the number of such entries is reported by AsyncDropped.
+// Close drains the buffered entries before closing the sink; entries logged after Close are dropped.
+func WithAsync(bufferSize int) LoggerOption {
+	return func(l *Logger) {
+		l.async = newAsyncWriter(bufferSize)
+	}
+}
+
+// AsyncDropped returns the number of entries dropped because the buffer of WithAsync was full or closed.
+func (l *Logger) AsyncDropped() uint64 {
+	if l.async == nil {
+		return 0
+	}
+	return l.async.dropped.Load()
+}
+
+type asyncRecord struct {
+	l   *Logger
+	ctx context.Context
+	rs  []slog.Record
+}
+
+type asyncWriter struct {
+	queue   chan asyncRecord
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newAsyncWriter(bufferSize int) *asyncWriter {
+	a := &asyncWriter{
+		queue: make(chan asyncRecord, bufferSize),
+		done:  make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *asyncWriter) run() {
+	defer close(a.done)
+	for item := range a.queue {
+		item.l.handleRecords(item.ctx, item.rs)
+	}
+}
+
+// enqueue reports whether the records have been accepted. The records are written at once.
+func (a *asyncWriter) enqueue(l *Logger, ctx context.Context, rs []slog.Record) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		a.dropped.Add(uint64(len(rs)))
+		return false
+	}
+	select {
+	case a.queue <- asyncRecord{l: l, ctx: context.WithoutCancel(ctx), rs: rs}:
+		return true
+	default:
+		a.dropped.Add(uint64(len(rs)))
+		return false
+	}
+}
+
+// drain stops accepting records and waits for the buffered ones to be written.
+func (a *asyncWriter) drain() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
@@ -0,0 +1,115 @@
+package logger_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+// gateWriter blocks each write until the gate is opened, telling when it has started one.
+type gateWriter struct {
+	capture *logtest.Capture
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.started <- struct{}{}
+	<-w.gate
+	return w.capture.Write(p)
+}
+
+func TestAsyncBufferFull(t *testing.T) {
+	w := &gateWriter{capture: &logtest.Capture{}, started: make(chan struct{}, 8), gate: make(chan struct{})}
+	var mu sync.Mutex
+	var dropped []string
+	l := logger.New(w, "p", logger.LevelInfo,
+		logger.WithAsync(1),
+		logger.WithDroppedEntryCallback(func(_ context.Context, entry logger.Entry, reason logger.DropReason) {
+			mu.Lock()
+			defer mu.Unlock()
+			dropped = append(dropped, fmt.Sprintf("%s: %s", entry.Message(), reason))
+		}),
+	)
+	ctx := context.Background()
+
+	l.Info(ctx, "first")
+	<-w.started // the first one is out of the buffer
+	l.Info(ctx, "second")
+	l.Info(ctx, "third") // the buffer is full
+	l.WriteBatch(ctx, []logger.Entry{
+		logger.NewEntry(logger.LevelInfo, "batch 1"),
+		logger.NewEntry(logger.LevelInfo, "batch 2"),
+	})
+	if got := l.AsyncDropped(); got != 3 {
+		t.Errorf("AsyncDropped() = %d, want 3 for the full buffer", got)
+	}
+
+	close(w.gate)
+	if err := l.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	l.Info(ctx, "after close")
+	if got := l.AsyncDropped(); got != 4 {
+		t.Errorf("AsyncDropped() = %d, want 4 with the one after Close", got)
+	}
+
+	var written []string
+	for _, e := range w.capture.Entries() {
+		written = append(written, e.Message)
+	}
+	if fmt.Sprint(written) != "[first second]" {
+		t.Errorf("written = %q, want the accepted ones drained by Close", written)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := fmt.Sprint([]string{
+		"third: " + logger.DropReasonBufferFull.String(),
+		"batch 1: " + logger.DropReasonBufferFull.String(),
+		"batch 2: " + logger.DropReasonBufferFull.String(),
+		"after close: " + logger.DropReasonBufferFull.String(),
+	})
+	if fmt.Sprint(dropped) != want {
+		t.Errorf("dropped = %q, want %s", dropped, want)
+	}
+}
+
+func TestAsyncOrder(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithAsync(1000))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.Info(ctx, fmt.Sprintf("%d-%d", g, i))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := l.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := capture.Entries()
+	if len(entries) != 400 || l.AsyncDropped() != 0 {
+		t.Fatalf("got %d entries with %d dropped, want all the 400 drained by Close", len(entries), l.AsyncDropped())
+	}
+	next := make([]int, 4)
+	for _, e := range entries {
+		var g, i int
+		if _, err := fmt.Sscanf(e.Message, "%d-%d", &g, &i); err != nil {
+			t.Fatal(err)
+		}
+		if i != next[g] {
+			t.Fatalf("got %q after %d entries of the goroutine, want them in the logged order", e.Message, next[g])
+		}
+		next[g]++
+	}
+}
@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultAsyncQueueSize = 1024
+
+type asyncHandlerConfig struct {
+	queueSize int
+	onError   func(context.Context, error)
+}
+
+// AsyncHandlerOption configures the handler of NewAsyncHandler.
+type AsyncHandlerOption func(*asyncHandlerConfig)
+
+// WithQueueSize sets the number of the records buffered by the handler, which is 1024 by default.
+func WithQueueSize(size int) AsyncHandlerOption {
+	return func(c *asyncHandlerConfig) {
+		c.queueSize = size
+	}
+}
+
+// WithAsyncErrorHandler sets the function called with the errors of the inner handler on the background goroutine,
+// which prints them to os.Stderr by default.
+func WithAsyncErrorHandler(f func(ctx context.Context, err error)) AsyncHandlerOption {
+	return func(c *asyncHandlerConfig) {
+		c.onError = f
+	}
+}
+
+// AsyncHandler is the slog.Handler which hands the records over to a background goroutine writing them to the inner handler,
+// so that the caller does not wait for stderr or the network. It is the counterpart of WithAsync for any slog.Handler,
+// e.g. the one of NewGCPHandler.
+//
+// The records are written in the order they are handled, and keep the time they are handled,
+// which the handler of NewGCPHandler writes as the timestamp. When the queue is full, the record is dropped
+// rather than blocking the caller, and the number of such records is reported by Dropped.
+// The handlers derived by WithAttrs and WithGroup share the queue.
+type AsyncHandler struct {
+	inner slog.Handler
+	queue *asyncQueue
+}
+
+// NewAsyncHandler returns the handler writing the records to inner asynchronously.
+// Call Close to write the buffered records before the program exits.
+func NewAsyncHandler(inner slog.Handler, opts ...AsyncHandlerOption) *AsyncHandler {
+	c := asyncHandlerConfig{
+		queueSize: defaultAsyncQueueSize,
+		onError:   printHandlerError,
+	}
+	for _, apply := range opts {
+		apply(&c)
+	}
+	q := &asyncQueue{
+		items:   make(chan asyncItem, c.queueSize),
+		done:    make(chan struct{}),
+		onError: c.onError,
+	}
+	go q.run()
+	return &AsyncHandler{inner: inner, queue: q}
+}
+
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle enqueues the record. It never returns an error; the errors of the inner handler are passed to
+// the function set by WithAsyncErrorHandler.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.queue.enqueue(asyncItem{ctx: context.WithoutCancel(ctx), h: h.inner, r: r.Clone()})
+	return nil
+}
+
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{inner: h.inner.WithAttrs(attrs), queue: h.queue}
+}
+
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{inner: h.inner.WithGroup(name), queue: h.queue}
+}
+
+// Flush waits for the records handled so far to be written, or for ctx to be done.
+// It returns immediately after Close.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	return h.queue.flush(ctx)
+}
+
+// Close stops accepting records and waits for the buffered ones to be written.
+// The records handled after Close are dropped. It is safe to call Close more than once.
+func (h *AsyncHandler) Close() error {
+	h.queue.close()
+	return nil
+}
+
+// Dropped returns the number of the records dropped because the queue was full or closed.
+func (h *AsyncHandler) Dropped() uint64 {
+	return h.queue.dropped.Load()
+}
+
+// asyncItem is either a record to write or a marker of Flush, which has flushed.
+type asyncItem struct {
+	ctx     context.Context
+	h       slog.Handler
+	r       slog.Record
+	flushed chan struct{}
+}
+
+type asyncQueue struct {
+	items   chan asyncItem
+	done    chan struct{}
+	dropped atomic.Uint64
+	onError func(context.Context, error)
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	for item := range q.items {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		if err := item.h.Handle(item.ctx, item.r); err != nil {
+			q.onError(item.ctx, err)
+		}
+	}
+}
+
+func (q *asyncQueue) enqueue(item asyncItem) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		q.dropped.Add(1)
+		return
+	}
+	select {
+	case q.items <- item:
+	default:
+		q.dropped.Add(1)
+	}
+}
+
+func (q *asyncQueue) flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return nil
+	}
+	// unlike the records, the marker waits for the room of the queue
+	select {
+	case q.items <- asyncItem{flushed: flushed}:
+		q.mu.RUnlock()
+	case <-ctx.Done():
+		q.mu.RUnlock()
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.items)
+	}
+	q.mu.Unlock()
+	<-q.done
+}
@@ -0,0 +1,118 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestAsyncHandlerRecordTime(t *testing.T) {
+	c := &logtest.Capture{}
+	dequeued := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	h := logger.NewAsyncHandler(logger.NewGCPHandler(c, "p", logger.WithClock(func() time.Time { return dequeued })))
+	defer h.Close()
+	ctx := context.Background()
+
+	logged := time.Date(2024, 1, 2, 3, 4, 0, 6, time.UTC)
+	slog.New(h).With(slog.String("service", "api")).Info("with attrs")
+	if err := h.Handle(ctx, slog.NewRecord(logged, slog.LevelInfo, "hello", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := c.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Attrs["service"] != "api" {
+		t.Errorf("attrs = %v, want those of WithAttrs", entries[0].Attrs)
+	}
+	if got := entries[1].Attrs[logger.KeyTime]; got != "2024-01-02T03:04:00.000000006Z" {
+		t.Errorf("time = %v, want the time of logging", got)
+	}
+}
+
+// gateHandler blocks each record until the gate is opened, telling when it has started handling one.
+type gateHandler struct {
+	slog.Handler
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func (h *gateHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.started <- struct{}{}
+	<-h.gate
+	return h.Handler.Handle(ctx, r)
+}
+
+func TestAsyncHandlerQueue(t *testing.T) {
+	c := &logtest.Capture{}
+	inner := &gateHandler{Handler: logger.NewGCPHandler(c, "p"), started: make(chan struct{}, 8), gate: make(chan struct{})}
+	h := logger.NewAsyncHandler(inner, logger.WithQueueSize(1))
+	ctx := context.Background()
+	now := time.Now()
+
+	h.Handle(ctx, slog.NewRecord(now, slog.LevelInfo, "first", 0))
+	<-inner.started // the first one is out of the queue
+	h.Handle(ctx, slog.NewRecord(now, slog.LevelInfo, "second", 0))
+	h.Handle(ctx, slog.NewRecord(now, slog.LevelInfo, "dropped", 0)) // the queue is full
+	if got := h.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1 for the full queue", got)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := h.Flush(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush() = %v, want the error of ctx while the records are blocked", err)
+	}
+
+	close(inner.gate)
+	if err := h.Flush(ctx); err != nil {
+		t.Errorf("Flush() = %v, want nil", err)
+	}
+	h.Close()
+	h.Handle(ctx, slog.NewRecord(now, slog.LevelInfo, "after close", 0))
+	if got := h.Dropped(); got != 2 {
+		t.Errorf("Dropped() = %d, want 2 with the one after Close", got)
+	}
+	if err := h.Flush(ctx); err != nil {
+		t.Errorf("Flush() after Close = %v, want nil", err)
+	}
+
+	var got []string
+	for _, e := range c.Entries() {
+		got = append(got, e.Message)
+	}
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("messages = %q, want the accepted ones in order", got)
+	}
+}
+
+func TestAsyncHandlerCloseDrains(t *testing.T) {
+	c := &logtest.Capture{}
+	h := logger.NewAsyncHandler(logger.NewGCPHandler(c, "p"), logger.WithQueueSize(100))
+	l := slog.New(h)
+
+	for i := 0; i < 50; i++ {
+		l.Info("hello", slog.Int("i", i))
+	}
+	h.Close()
+	h.Close() // safe to call again
+
+	entries := c.Entries()
+	if len(entries) != 50 {
+		t.Fatalf("got %d entries, want 50 written by Close", len(entries))
+	}
+	for i, e := range entries {
+		if e.Attrs["i"] != float64(i) {
+			t.Fatalf("entries[%d] is %v, want the records in order", i, e.Attrs["i"])
+		}
+	}
+}
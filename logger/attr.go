@@ -0,0 +1,18 @@
+package logger
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Duration returns the attribute of the duration in milliseconds as a number,
+// so that the field can be filtered numerically, e.g. `jsonPayload.latency > 100`.
+// Name the key with the unit (e.g. "latency_ms") to make it clear.
+func Duration(key string, d time.Duration) slog.Attr {
+	return slog.Float64(key, float64(d)/float64(time.Millisecond))
+}
+
+// Bytes returns the attribute of the size in bytes as a number.
+func Bytes(key string, n int64) slog.Attr {
+	return slog.Int64(key, n)
+}
@@ -0,0 +1,29 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestNumericAttrs(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	l.Info(context.Background(), "hello", logger.WithAttrs(
+		logger.Duration("latency_ms", 1500*time.Microsecond),
+		logger.Bytes("size", 2048),
+	))
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	for key, want := range map[string]float64{"latency_ms": 1.5, "size": 2048} {
+		if got, ok := entries[0].Attrs[key].(float64); !ok || got != want {
+			t.Errorf("%s = %#v, want the number %v", key, entries[0].Attrs[key], want)
+		}
+	}
+}
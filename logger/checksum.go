@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/logging"
+)
+
+// WithEntryChecksum adds the SHA-256 checksum of each entry as the "checksum" attribute.
+//
+// The checksum is computed over the canonical form of the entry, which consists of
+// the following lines, each terminated by "\n":
+//   - the previous checksum in hex (only when WithHashChain is set; empty for the first entry)
+//   - the timestamp as emitted, i.e. RFC3339Nano in UTC with 9 fractional digits
+//   - the severity as emitted (e.g. "Error")
+//   - the message, quoted by strconv.Quote
+//   - every attribute in the emitted order as key=value, where the key of a nested attribute
+//     is joined with its group names by "." and the value is the resolved slog.Value.String()
+//     quoted by strconv.Quote, after the attribute is rewritten by the redactor set by WithRedactor
+//
+// The source location and the checksum itself are not part of the canonical form.
+func WithEntryChecksum() LoggerOption {
+	return func(l *Logger) {
+		if l.checksum == nil {
+			l.checksum = &checksummer{}
+		}
+	}
+}
+
+// WithHashChain enables WithEntryChecksum and chains each checksum to the previous one,
+// so that removing or reordering entries of the stream can be detected.
+func WithHashChain() LoggerOption {
+	return func(l *Logger) {
+		l.checksum = &checksummer{chain: true}
+	}
+}
+
+type checksummer struct {
+	chain bool
+
+	mu   sync.Mutex
+	prev string
+}
+
+// sign adds the checksum attribute to the record.
+// The returned function must be called after the record is handled,
+// so that the order of the output is consistent with the chain.
+// replace rewrites the attributes as the handler does, so that the checksum is of the emitted values.
+func (c *checksummer) sign(r *slog.Record, severity logging.Severity, replace func([]string, slog.Attr) slog.Attr) (done func()) {
+	if !c.chain {
+		r.AddAttrs(slog.String(KeyChecksum, digest(false, "", severity, *r, replace)))
+		return func() {}
+	}
+
+	c.mu.Lock()
+	sum := digest(true, c.prev, severity, *r, replace)
+	c.prev = sum
+	r.AddAttrs(slog.String(KeyChecksum, sum))
+	return c.mu.Unlock
+}
+
+func digest(chain bool, prev string, severity logging.Severity, r slog.Record, replace func([]string, slog.Attr) slog.Attr) string {
+	h := sha256.New()
+	if chain {
+		io.WriteString(h, prev+"\n")
+	}
+	io.WriteString(h, r.Time.UTC().Format(timeLayout)+"\n")
+	io.WriteString(h, severity.String()+"\n")
+	io.WriteString(h, strconv.Quote(r.Message)+"\n")
+	r.Attrs(func(a slog.Attr) bool {
+		writeCanonicalAttr(h, nil, a, replace)
+		return true
+	})
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeCanonicalAttr(h hash.Hash, groups []string, a slog.Attr, replace func([]string, slog.Attr) slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup && replace != nil {
+		if a = replace(groups, a); a.Equal(slog.Attr{}) {
+			return // dropped by the redactor
+		}
+		a.Value = a.Value.Resolve()
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(groups, a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			writeCanonicalAttr(h, groups, ga, replace)
+		}
+		return
+	}
+	key := strings.Join(groups, ".")
+	if key == "" {
+		key = a.Key
+	} else if a.Key != "" {
+		key += "." + a.Key
+	}
+	io.WriteString(h, key+"="+strconv.Quote(a.Value.String())+"\n")
+}
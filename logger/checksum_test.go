@@ -0,0 +1,48 @@
+package logger_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestEntryChecksumCanonicalForm(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	l, capture := logtest.NewCapture(
+		logger.WithClock(func() time.Time { return now }),
+		logger.WithEntryChecksum(),
+		logger.WithRedactor(func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "token" {
+				return slog.String(a.Key, "[REDACTED]")
+			}
+			return a
+		}),
+	)
+
+	l.Info(context.Background(), "hello", logger.WithAttrs(slog.String("token", "secret")))
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Attrs[logger.KeyTime] != "2024-01-02T03:04:05.000000000Z" || e.Attrs["token"] != "[REDACTED]" {
+		t.Fatalf("entry = %+v, want the timestamp and the redacted token", e)
+	}
+	canonical := "2024-01-02T03:04:05.000000000Z\n" +
+		"Info\n" +
+		strconv.Quote("hello") + "\n" +
+		logger.KeyInsertID + "=" + strconv.Quote(e.InsertID) + "\n" +
+		"token=" + strconv.Quote("[REDACTED]") + "\n"
+	sum := sha256.Sum256([]byte(canonical))
+	if got, want := e.Attrs[logger.KeyChecksum], hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("checksum = %v, want %v of the emitted values", got, want)
+	}
+}
@@ -0,0 +1,237 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"runtime"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+)
+
+// NewWithClient returns a logger which sends the entries to Cloud Logging through the client,
+// instead of writing them to an io.Writer. The entries are batched and uploaded asynchronously
+// by the client, and Logger.Close flushes them and closes the client.
+// The projectID used to format the traceID is read from GOOGLE_CLOUD_PROJECT; use WithProjectID to override it.
+func NewWithClient(client *logging.Client, logID string, minLevel slog.Level, opts ...LoggerOption) *Logger {
+	logger := newLogger(os.Getenv("GOOGLE_CLOUD_PROJECT"), minLevel, opts...)
+	logger.useClient(client, logID)
+	return logger
+}
+
+// WithCloudLogging makes New send the entries to Cloud Logging through the client as NewWithClient does,
+// instead of writing them to the io.Writer, which is ignored then. It is for the environments where
+// the standard output is not collected by an agent, such as GCE VMs without the Ops Agent or on-premises servers.
+// The client batches the entries and retries the transient failures of the API by itself.
+func WithCloudLogging(client *logging.Client, logID string) LoggerOption {
+	return func(l *Logger) {
+		l.cloudLogging = &cloudLogging{client: client, logID: logID}
+	}
+}
+
+type cloudLogging struct {
+	client *logging.Client
+	logID  string
+}
+
+// useClient makes the logger send the entries through the client.
+func (l *Logger) useClient(client *logging.Client, logID string) {
+	handler := &clientHandler{
+		logger:          client.Logger(logID),
+		level:           l.level,
+		replace:         l.redact,
+		messageKey:      l.messageKey,
+		severity:        l.severity,
+		serverTimestamp: l.serverTimestamp,
+	}
+	l.handler = handler
+	if client.OnError == nil {
+		client.OnError = func(err error) {
+			l.handleError(context.Background(), err)
+		}
+	}
+	l.lifecycle.closeSink = func() error {
+		return errors.Join(handler.logger.Flush(), client.Close())
+	}
+}
+
+// WithServerTimestamp makes the logger of NewWithClient or WithCloudLogging leave the timestamp of the entries to the client,
+// instead of the time the entries are logged. It has no effect on the loggers writing to an io.Writer.
+//
+// Note that the client stamps the entries with its local clock when they are handed over,
+// since the Cloud Logging API has no way for the server to assign the timestamp.
+// So the timestamp does not follow WithClock, and it is taken slightly later than the call of the logger.
+func WithServerTimestamp(enabled bool) LoggerOption {
+	return func(l *Logger) {
+		l.serverTimestamp = enabled
+	}
+}
+
+// clientHandler is a slog.Handler which converts the records into logging.Entry.
+// The special attributes prepared by Logger.write are mapped to the corresponding fields,
+// and the others are put in the JSON payload.
+type clientHandler struct {
+	logger          *logging.Logger
+	level           slog.Leveler
+	replace         func([]string, slog.Attr) slog.Attr
+	messageKey      string
+	severity        func(slog.Level) logging.Severity
+	serverTimestamp bool
+	attrs           []slog.Attr
+	groups          []string
+}
+
+func (h *clientHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *clientHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], nestAttrs(h.groups, attrs)...)
+	return &h2
+}
+
+func (h *clientHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+func (h *clientHandler) Handle(_ context.Context, r slog.Record) error {
+	e := logging.Entry{
+		Severity:       h.severity(r.Level),
+		SourceLocation: sourceLocation(r.PC),
+	}
+	if !h.serverTimestamp {
+		e.Timestamp = r.Time
+	}
+	payload := map[string]any{h.messageKey: r.Message}
+	for _, a := range h.attrs {
+		h.putAttr(payload, nil, a)
+	}
+
+	var rest []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		if len(h.groups) > 0 || !h.setField(&e, a) {
+			rest = append(rest, a)
+		}
+		return true
+	})
+	for _, a := range nestAttrs(h.groups, rest) {
+		h.putAttr(payload, nil, a)
+	}
+	e.Payload = payload
+
+	h.logger.Log(e)
+	return nil
+}
+
+// setField sets the special attribute to the field of the entry and reports whether a is special.
+func (h *clientHandler) setField(e *logging.Entry, a slog.Attr) bool {
+	switch a.Key {
+	case KeyInsertID:
+		e.InsertID = a.Value.String()
+	case KeyTrace:
+		e.Trace = a.Value.String()
+	case KeySpanID:
+		e.SpanID = a.Value.String()
+	case KeyTraceSampled:
+		v := a.Value.Resolve()
+		if v.Kind() != slog.KindBool { // e.g. the one of WithRawField, kept in the payload
+			return false
+		}
+		e.TraceSampled = v.Bool()
+	case KeyLabels:
+		v := a.Value.Resolve()
+		if v.Kind() != slog.KindGroup {
+			return false
+		}
+		e.Labels = make(map[string]string)
+		for _, la := range v.Group() {
+			e.Labels[la.Key] = la.Value.String()
+		}
+	case KeyHTTPRequest:
+		v, ok := a.Value.Any().(httpRequestValue)
+		if !ok {
+			return false
+		}
+		e.HTTPRequest = v.HTTPRequest
+	case KeyOperation:
+		v, ok := a.Value.Any().(operationValue)
+		if !ok {
+			return false
+		}
+		e.Operation = v.LogEntryOperation
+	default:
+		return false
+	}
+	return true
+}
+
+func sourceLocation(pc uintptr) *loggingpb.LogEntrySourceLocation {
+	if pc == 0 {
+		return nil
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return &loggingpb.LogEntrySourceLocation{
+		File:     frame.File,
+		Line:     int64(frame.Line),
+		Function: frame.Function,
+	}
+}
+
+// nestAttrs puts attrs in the groups, from the outermost one.
+func nestAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{slog.Group(groups[i], anyAttrs(attrs)...)}
+	}
+	return attrs
+}
+
+// putAttr puts the attribute into the JSON payload. Groups are merged into nested objects.
+func (h *clientHandler) putAttr(payload map[string]any, groups []string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		if a = h.replace(groups, slog.Attr{Key: a.Key, Value: v}); a.Key != "" {
+			payload[a.Key] = jsonValue(a.Value.Resolve())
+		}
+		return
+	}
+
+	dst := payload
+	if a.Key != "" {
+		m, ok := payload[a.Key].(map[string]any)
+		if !ok {
+			m = make(map[string]any)
+			payload[a.Key] = m
+		}
+		dst = m
+		groups = append(slices.Clip(groups), a.Key)
+	}
+	for _, ga := range v.Group() {
+		h.putAttr(dst, groups, ga)
+	}
+}
+
+func jsonValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		return v.Any()
+	default:
+		return v.Any()
+	}
+}
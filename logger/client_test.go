@@ -0,0 +1,93 @@
+package logger_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/ebi-yade/osuite/logger"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// fakeLoggingServer records the entries sent by the client of Cloud Logging,
+// except the diagnostic one which the client sends by itself.
+type fakeLoggingServer struct {
+	loggingpb.UnimplementedLoggingServiceV2Server
+	mu      sync.Mutex
+	entries []*loggingpb.LogEntry
+}
+
+func (s *fakeLoggingServer) WriteLogEntries(_ context.Context, req *loggingpb.WriteLogEntriesRequest) (*loggingpb.WriteLogEntriesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range req.Entries {
+		if !strings.HasSuffix(e.LogName, "/diagnostic-log") {
+			s.entries = append(s.entries, e)
+		}
+	}
+	return &loggingpb.WriteLogEntriesResponse{}, nil
+}
+
+// newClientLogger returns the logger of NewWithClient sending the entries to the fake server.
+func newClientLogger(t *testing.T, opts ...logger.LoggerOption) (*logger.Logger, *fakeLoggingServer) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeLoggingServer{}
+	srv := grpc.NewServer()
+	loggingpb.RegisterLoggingServiceV2Server(srv, fake)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	client, err := logging.NewClient(context.Background(), "projects/p",
+		option.WithEndpoint(lis.Addr().String()),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger.NewWithClient(client, "test", logger.LevelDefault, opts...), fake
+}
+
+func TestClientMistypedFields(t *testing.T) {
+	l, fake := newClientLogger(t)
+	ctx := context.Background()
+	l.Info(ctx, "raw fields",
+		logger.WithRawField(logger.KeyTraceSampled, "yes"),
+		logger.WithRawField(logger.KeyLabels, "not a group"),
+	)
+	l.Info(ctx, "labels", logger.WithLabels(map[string]string{"team": "core"}))
+	if err := l.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(fake.entries))
+	}
+	raw := fake.entries[0]
+	if raw.TraceSampled || raw.Labels != nil {
+		t.Errorf("trace_sampled = %v, labels = %v, want them unset by the mistyped values", raw.TraceSampled, raw.Labels)
+	}
+	fields := raw.GetJsonPayload().GetFields()
+	if got := fields[logger.KeyTraceSampled].GetStringValue(); got != "yes" {
+		t.Errorf("payload %s = %q, want the mistyped value kept", logger.KeyTraceSampled, got)
+	}
+	if got := fields[logger.KeyLabels].GetStringValue(); got != "not a group" {
+		t.Errorf("payload %s = %q, want the mistyped value kept", logger.KeyLabels, got)
+	}
+
+	if got := fake.entries[1].Labels["team"]; got != "core" {
+		t.Errorf("labels = %v, want those of WithLabels", fake.entries[1].Labels)
+	}
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+type lifecycle struct {
+	closeSink func() error
+
+	once sync.Once
+	err  error
+}
+
+// Close flushes the buffered entries and releases the resources held by the logger.
+// For the logger writing to an io.Writer, the writer is flushed if it has a Flush method;
+// otherwise Close does nothing and returns nil, so that callers can always defer it.
+// Close is idempotent and safe for concurrent use, so it can be called from a signal handler.
+// The loggers derived by With share the state with l, so closing one of them closes all of them.
+func (l *Logger) Close(ctx context.Context) error {
+	l.lifecycle.once.Do(func() {
+		done := make(chan error, 1)
+		go func() {
+			done <- l.close()
+		}()
+		select {
+		case err := <-done:
+			l.lifecycle.err = err
+		case <-ctx.Done():
+			l.lifecycle.err = ctx.Err()
+		}
+	})
+	return l.lifecycle.err
+}
+
+func (l *Logger) close() error {
+	if l.aggregator != nil {
+		l.aggregator.flush()
+	}
+	if l.limiter != nil {
+		l.limiter.flush()
+	}
+	if l.async != nil {
+		l.async.drain()
+	}
+	if l.lifecycle.closeSink != nil {
+		return l.lifecycle.closeSink()
+	}
+	return nil
+}
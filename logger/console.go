@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+)
+
+// consoleHandler writes the records as human-readable lines such as:
+//
+//	15:04:05.000 INFO     message key=value trace=projects/p/traces/t (file.go:12)
+type consoleHandler struct {
+	mu       *sync.Mutex
+	w        io.Writer
+	level    slog.Leveler
+	replace  func([]string, slog.Attr) slog.Attr
+	severity func(slog.Level) logging.Severity
+	color    bool
+	goas     []groupOrAttrs
+}
+
+func newConsoleHandler(w io.Writer, level slog.Leveler, replace func([]string, slog.Attr) slog.Attr, severity func(slog.Level) logging.Severity) *consoleHandler {
+	return &consoleHandler{
+		mu:       &sync.Mutex{},
+		w:        w,
+		level:    level,
+		replace:  replace,
+		severity: severity,
+		color:    isTerminal(w) && os.Getenv("NO_COLOR") == "",
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	if sw, ok := w.(*swapWriter); ok {
+		w = sw.current()
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func severityColor(level slog.Level) string {
+	switch {
+	case level >= LevelCritical:
+		return "\x1b[1;31m"
+	case level >= LevelError:
+		return "\x1b[31m"
+	case level >= LevelWarning:
+		return "\x1b[33m"
+	case level >= LevelNotice:
+		return "\x1b[36m"
+	case level >= LevelInfo:
+		return "\x1b[34m"
+	default:
+		return "\x1b[90m"
+	}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	h.paint(&buf, ansiDim, r.Time.Format(time.TimeOnly+".000"))
+	buf.WriteByte(' ')
+	severity := h.severity(r.Level)
+	h.paint(&buf, severityColor(slog.Level(severity)), padRight(strings.ToUpper(severity.String()), 9))
+	buf.WriteString(r.Message)
+
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for _, a := range buildAttrs(h.goas, attrs) {
+		h.appendAttr(&buf, nil, a)
+	}
+
+	if r.PC != 0 {
+		src := sourceLocation(r.PC)
+		buf.WriteByte(' ')
+		h.paint(&buf, ansiDim, "("+filepath.Base(src.File)+":"+strconv.FormatInt(src.Line, 10)+")")
+	}
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *consoleHandler) appendAttr(buf *bytes.Buffer, groups []string, a slog.Attr) {
+	if len(groups) == 0 && (a.Key == KeyInsertID || a.Key == logAttrReporting.Key || a.Key == KeyStackTrace) {
+		return // noisy on console
+	}
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(buf, groups, ga)
+		}
+		return
+	}
+	if a = h.replace(groups, a); a.Key == "" {
+		return
+	}
+
+	key := strings.TrimPrefix(strings.Join(append(slices.Clip(groups), a.Key), "."), "logging.googleapis.com/")
+	v := a.Value.Resolve()
+	s := v.String()
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		s = strconv.Quote(s)
+	}
+	buf.WriteByte(' ')
+	h.paint(buf, ansiDim, key+"=")
+	buf.WriteString(s)
+}
+
+func (h *consoleHandler) paint(buf *bytes.Buffer, color, s string) {
+	if !h.color {
+		buf.WriteString(s)
+		return
+	}
+	buf.WriteString(color + s + ansiReset)
+}
+
+func padRight(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), groupOrAttrs{attrs: slices.Clone(attrs)})
+	return &h2
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), groupOrAttrs{group: name})
+	return &h2
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+type contextAttrsKey struct{}
+
+// ContextWithAttrs returns a copy of ctx carrying the attributes, which are added to every entry logged with it,
+// after the attributes of With and before those of the entry.
+// The attributes are merged with those already carried by ctx, and the new one replaces the old one on the same key,
+// so that adding the same keys repeatedly does not grow the context.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	parent := attrsFromContext(ctx)
+	merged := make([]slog.Attr, 0, len(parent)+len(attrs))
+	merged = append(merged, parent...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, contextAttrsKey{}, dedupeAttrs(merged))
+}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(contextAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+type contextLoggerKey struct{}
+
+// Into returns a copy of ctx carrying the logger, e.g. the request-scoped one given by Logger.ForRequest,
+// so that the functions handling the request get it by From instead of taking it as an argument.
+func Into(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextLoggerKey{}, l)
+}
+
+// From returns the logger carried by ctx, or the fallback set by SetFallback if there is none.
+// The fallback is the logger of NewNop by default, so From never returns nil.
+func From(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextLoggerKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	if l := fallbackLogger.Load(); l != nil {
+		return l
+	}
+	return nopLogger
+}
+
+var (
+	fallbackLogger atomic.Pointer[Logger]
+	nopLogger      = NewNop()
+)
+
+// SetFallback sets the logger returned by From for the contexts without a logger, e.g. the logger of the application.
+// nil restores the logger of NewNop.
+func SetFallback(l *Logger) {
+	fallbackLogger.Store(l)
+}
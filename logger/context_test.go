@@ -0,0 +1,41 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestContextWithAttrs(t *testing.T) {
+	base, capture := logtest.NewCapture()
+	l := base.With(slog.String("tenant_id", "logger"), slog.String("service", "api"))
+
+	ctx := logger.ContextWithAttrs(context.Background(), slog.String("tenant_id", "t1"), slog.String("request_id", "r1"))
+	ctx = logger.ContextWithAttrs(ctx, slog.String("user_id", "u1"))
+	for i := 0; i < 3; i++ {
+		ctx = logger.ContextWithAttrs(ctx, slog.String("request_id", "r2")) // replaces the old one
+	}
+	l.Info(ctx, "hello", logger.WithAttrs(slog.String("user_id", "u2")))
+	l.Info(context.Background(), "without attrs")
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for key, want := range map[string]string{
+		"tenant_id":  "t1", // context > logger
+		"service":    "api",
+		"request_id": "r2",
+		"user_id":    "u2", // entry > context
+	} {
+		if got := entries[0].Attrs[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+	if _, ok := entries[1].Attrs["request_id"]; ok || entries[1].Attrs["tenant_id"] != "logger" {
+		t.Errorf("attrs = %v, want only those of the logger", entries[1].Attrs)
+	}
+}
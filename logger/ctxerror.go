@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// WithContextErrorPolicy makes the error methods such as Logger.Error downgrade the errors caused by the context,
+// which are often just the cancellations by the clients: the error matching context.Canceled by errors.Is
+// is logged at canceled, and the one matching context.DeadlineExceeded at deadline.
+// The downgraded entries are neither reported as errors nor have stack traces.
+func WithContextErrorPolicy(canceled, deadline slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.contextErrorPolicy = &contextErrorPolicy{canceled: canceled, deadline: deadline}
+	}
+}
+
+type contextErrorPolicy struct {
+	canceled slog.Level
+	deadline slog.Level
+}
+
+// apply downgrades the entry if its error is caused by the context.
+func (p *contextErrorPolicy) apply(entry *Entry) {
+	switch {
+	case errors.Is(entry.err, context.Canceled):
+		entry.level = p.canceled
+	case errors.Is(entry.err, context.DeadlineExceeded):
+		entry.level = p.deadline
+	default:
+		return
+	}
+	entry.errorReport = false
+	entry.stackTrace = false
+}
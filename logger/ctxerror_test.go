@@ -0,0 +1,53 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestContextErrorPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		err        error
+		wantLevel  slog.Level
+		wantReport bool
+	}{
+		{name: "canceled", err: fmt.Errorf("query: %w", context.Canceled), wantLevel: logger.LevelInfo},
+		{name: "deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), wantLevel: logger.LevelWarning},
+		{name: "other", err: errors.New("query failed"), wantLevel: logger.LevelError, wantReport: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l, capture := logtest.NewCapture(logger.WithContextErrorPolicy(logger.LevelInfo, logger.LevelWarning))
+
+			l.Error(context.Background(), tt.err)
+
+			entries := capture.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.wantLevel || e.ErrorReport != tt.wantReport {
+				t.Errorf("level = %v, error report = %t, want %v, %t", e.Level, e.ErrorReport, tt.wantLevel, tt.wantReport)
+			}
+			if _, ok := e.Attrs[logger.KeyStackTrace]; ok != tt.wantReport {
+				t.Errorf("has stack trace = %t, want %t", ok, tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestContextErrorWithoutPolicy(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	l.Error(context.Background(), context.Canceled)
+
+	if entries := capture.Entries(); len(entries) != 1 || entries[0].Level != logger.LevelError || !entries[0].ErrorReport {
+		t.Errorf("entries = %+v, want the error report", entries)
+	}
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// DropReason describes why an entry was not emitted.
+type DropReason int
+
+const (
+	// DropReasonLevelFiltered means the level of the entry is below the minimum level.
+	DropReasonLevelFiltered DropReason = iota + 1
+	// DropReasonSampled means the entry was dropped by a sampler.
+	DropReasonSampled
+	// DropReasonThrottled means the entry was dropped by a throttle or a rate limit.
+	DropReasonThrottled
+	// DropReasonBufferFull means the entry was dropped because the buffer of WithAsync was full.
+	DropReasonBufferFull
+	// DropReasonContextCanceled means the entry was dropped because the context was done.
+	DropReasonContextCanceled
+)
+
+func (r DropReason) String() string {
+	switch r {
+	case DropReasonLevelFiltered:
+		return "LevelFiltered"
+	case DropReasonSampled:
+		return "Sampled"
+	case DropReasonThrottled:
+		return "Throttled"
+	case DropReasonBufferFull:
+		return "BufferFull"
+	case DropReasonContextCanceled:
+		return "ContextCanceled"
+	default:
+		return "Unknown"
+	}
+}
+
+// WithDroppedEntryCallback sets the function called whenever an entry is not emitted.
+// The callback runs synchronously on the goroutine of the caller, so keep it cheap.
+func WithDroppedEntryCallback(f func(ctx context.Context, entry Entry, reason DropReason)) LoggerOption {
+	return func(l *Logger) {
+		l.onDrop = f
+	}
+}
+
+// WithEntryCallback adds the function called with every entry to be written, after the level, the sampler,
+// WithAggregation and WithErrorRateLimit, e.g. to mirror the entries to the other observability tools.
+// The callbacks run synchronously on the goroutine of the caller in the order they are added, so keep them cheap.
+func WithEntryCallback(f func(ctx context.Context, entry Entry)) LoggerOption {
+	return func(l *Logger) {
+		if prev := l.onEntry; prev != nil {
+			l.onEntry = func(ctx context.Context, entry Entry) {
+				prev(ctx, entry)
+				f(ctx, entry)
+			}
+			return
+		}
+		l.onEntry = f
+	}
+}
+
+// WithMetrics sets the function called with the level of every entry the handler has written successfully,
+// e.g. to count the emitted entries by severity. It is not called for the dropped or disabled entries,
+// and an aggregated summary counts as one entry. With WithAsync, it runs on the background goroutine.
+func WithMetrics(f func(level slog.Level)) LoggerOption {
+	return func(l *Logger) {
+		l.metrics = f
+	}
+}
+
+// WithSkipOnCanceledContext makes the logger drop the entries logged with a done context
+// before any work is done for them. Entries at LevelError or above are always emitted,
+// because they are often logged precisely because the context was canceled.
+func WithSkipOnCanceledContext(skip bool) LoggerOption {
+	return func(l *Logger) {
+		l.skipCanceled = skip
+	}
+}
+
+// WithSampler sets the function to decide whether each enabled entry is emitted,
+// e.g. to emit one in N debug entries. It is called before the source location, the timestamp
+// and the insertId are computed, so that the dropped entries cost little.
+func WithSampler(f func(level slog.Level) bool) LoggerOption {
+	return func(l *Logger) {
+		l.sampler = f
+	}
+}
+
+// sampled consults the sampler and reports whether the entry should be emitted.
+func (l *Logger) sampled(ctx context.Context, entry Entry) bool {
+	if l.sampler == nil || l.sampler(entry.level) {
+		return true
+	}
+	l.drop(ctx, entry, DropReasonSampled)
+	return false
+}
+
+func (l *Logger) drop(ctx context.Context, entry Entry, reason DropReason) {
+	if l.onDrop != nil {
+		l.onDrop(ctx, entry, reason)
+	}
+}
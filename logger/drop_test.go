@@ -0,0 +1,138 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestEntryCallbacks(t *testing.T) {
+	var first, second []string
+	l, capture := logtest.NewCapture(
+		logger.WithErrorRateLimit(time.Hour, 1),
+		logger.WithEntryCallback(func(_ context.Context, entry logger.Entry) {
+			first = append(first, entry.Message())
+		}),
+		logger.WithEntryCallback(func(_ context.Context, entry logger.Entry) {
+			second = append(second, entry.Message())
+		}),
+	)
+	ctx := context.Background()
+
+	l.Info(ctx, "hello")
+	l.Error(ctx, errors.New("failed"))
+	l.Error(ctx, errors.New("failed")) // suppressed by the rate limit
+
+	if got := len(capture.Entries()); got != 2 {
+		t.Fatalf("got %d entries, want 2", got)
+	}
+	for name, got := range map[string][]string{"first": first, "second": second} {
+		if len(got) != 2 || got[0] != "hello" || got[1] != "failed" {
+			t.Errorf("%s callback got %q, want the written entries", name, got)
+		}
+	}
+}
+
+func TestSampler(t *testing.T) {
+	var debugs, clock, insertIDs int
+	l, capture := logtest.NewCapture(
+		logger.WithSampler(func(level slog.Level) bool {
+			if level != logger.LevelDebug {
+				return true
+			}
+			debugs++
+			return debugs%3 == 1 // one in three
+		}),
+		logger.WithClock(func() time.Time {
+			clock++
+			return time.Now()
+		}),
+		logger.WithInsertIDFunc(func(context.Context) string {
+			insertIDs++
+			return fmt.Sprint(insertIDs)
+		}),
+	)
+	ctx := context.Background()
+
+	for i := 0; i < 6; i++ {
+		l.Debug(ctx, fmt.Sprint(i))
+	}
+	l.Info(ctx, "info")
+
+	var got []string
+	for _, e := range capture.Entries() {
+		got = append(got, e.Message)
+	}
+	if fmt.Sprint(got) != "[0 3 info]" {
+		t.Errorf("messages = %q, want one in three debug entries and the info one", got)
+	}
+	if clock != 3 || insertIDs != 3 {
+		t.Errorf("clock called %d times, insertId generated %d times, want 3 each for the emitted entries", clock, insertIDs)
+	}
+}
+
+func TestSkipOnCanceledContext(t *testing.T) {
+	var reasons []logger.DropReason
+	l, capture := logtest.NewCapture(
+		logger.WithSkipOnCanceledContext(true),
+		logger.WithDroppedEntryCallback(func(_ context.Context, _ logger.Entry, reason logger.DropReason) {
+			reasons = append(reasons, reason)
+		}),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	l.Info(ctx, "skipped")
+	l.Warn(ctx, "skipped")
+	l.Error(ctx, errors.New("emitted"))
+	l.Info(context.Background(), "emitted")
+
+	var got []string
+	for _, e := range capture.Entries() {
+		got = append(got, e.Message)
+	}
+	if fmt.Sprint(got) != "[emitted emitted]" {
+		t.Errorf("messages = %q, want the error and the one with the live context", got)
+	}
+	if len(reasons) != 2 || reasons[0] != logger.DropReasonContextCanceled || reasons[1] != logger.DropReasonContextCanceled {
+		t.Errorf("reasons = %v, want 2 of %v", reasons, logger.DropReasonContextCanceled)
+	}
+}
+
+func TestCanceledContextEmittedByDefault(t *testing.T) {
+	l, capture := logtest.NewCapture()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	l.Info(ctx, "emitted")
+
+	if got := len(capture.Entries()); got != 1 {
+		t.Errorf("got %d entries, want 1", got)
+	}
+}
+
+func TestDroppedEntryAllocs(t *testing.T) {
+	var dropped int
+	for name, l := range map[string]*logger.Logger{
+		"disabled": logger.New(io.Discard, "p", logger.LevelInfo),
+		"callback": logger.New(io.Discard, "p", logger.LevelInfo, logger.WithDroppedEntryCallback(
+			func(_ context.Context, _ logger.Entry, _ logger.DropReason) { dropped++ },
+		)),
+		"sampled": logger.New(io.Discard, "p", logger.LevelDebug, logger.WithSampler(func(slog.Level) bool { return false })),
+	} {
+		ctx := context.Background()
+		if allocs := testing.AllocsPerRun(100, func() { l.Debug(ctx, "dropped") }); allocs != 0 {
+			t.Errorf("%s: got %v allocs per entry, want none for the dropped one", name, allocs)
+		}
+	}
+	if dropped == 0 {
+		t.Error("the callback is not called")
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// WithErrorDetails sets whether the entries of the error methods have the "errors" attribute,
+// which lists the errors of the chain with their types and messages, so that they can be queried by type.
+// The errors joined by errors.Join or the like are listed in depth-first order.
+func WithErrorDetails(enabled bool) LoggerOption {
+	return func(l *Logger) {
+		l.errorDetails = enabled
+	}
+}
+
+type errorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// errorDetailsAttr lists the errors of the chain of err.
+func errorDetailsAttr(err error) slog.Attr {
+	var details []errorDetail
+	walkErrors(err, func(err error) {
+		details = append(details, errorDetail{Type: fmt.Sprintf("%T", err), Message: err.Error()})
+	})
+	return slog.Any(KeyErrors, details)
+}
+
+// Attributer is implemented by the errors carrying the structured fields, e.g. the code of a domain error
+// or whether it is retryable. The attributes are added to the entries of the error methods such as Logger.Error
+// instead of being flattened into the message.
+type Attributer interface {
+	LogAttrs() []slog.Attr
+}
+
+// errorAttrs collects the attributes of the errors in the chain of err which implement Attributer,
+// or slog.LogValuer resolved to a group. The outer errors are put later to take precedence on the same key.
+func errorAttrs(err error) []slog.Attr {
+	var chain [][]slog.Attr
+	walkErrors(err, func(err error) {
+		switch v := err.(type) {
+		case Attributer:
+			chain = append(chain, v.LogAttrs())
+		case slog.LogValuer:
+			if rv := slog.AnyValue(v).Resolve(); rv.Kind() == slog.KindGroup {
+				chain = append(chain, rv.Group())
+			}
+		}
+	})
+	var attrs []slog.Attr
+	for i := len(chain) - 1; i >= 0; i-- {
+		attrs = append(attrs, chain[i]...)
+	}
+	return attrs
+}
+
+// walkErrors calls f with the errors of the chain of err in depth-first order, including those joined by errors.Join,
+// visiting each error at most once and at most maxErrorChain errors.
+func walkErrors(err error, f func(error)) {
+	visited := make(map[error]bool)
+	n := 0
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil || n >= maxErrorChain {
+			return
+		}
+		if reflect.TypeOf(err).Comparable() {
+			if visited[err] {
+				return
+			}
+			visited[err] = true
+		}
+		n++
+		f(err)
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(u.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, err := range u.Unwrap() {
+				walk(err)
+			}
+		}
+	}
+	walk(err)
+}
@@ -0,0 +1,76 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+// cycleError unwraps to next, which may lead back to itself.
+type cycleError struct {
+	msg  string
+	next error
+}
+
+func (e *cycleError) Error() string { return e.msg }
+func (e *cycleError) Unwrap() error { return e.next }
+
+// sliceError is not comparable, so it cannot be told from the errors already visited.
+type sliceError []string
+
+func (e sliceError) Error() string { return "slice" }
+func (e sliceError) Unwrap() error { return e }
+
+// errorDetails logs err with WithErrorDetails and returns the types and the messages of the "errors" attribute.
+func errorDetails(t *testing.T, err error) (types, messages []string) {
+	t.Helper()
+	l, capture := logtest.NewCapture(logger.WithErrorDetails(true))
+	l.Error(context.Background(), err)
+	details, _ := capture.Entries()[0].Attrs[logger.KeyErrors].([]any)
+	for _, d := range details {
+		m, _ := d.(map[string]any)
+		types = append(types, fmt.Sprint(m["type"]))
+		messages = append(messages, fmt.Sprint(m["message"]))
+	}
+	return types, messages
+}
+
+func TestErrorDetailsJoin(t *testing.T) {
+	err := errors.Join(errors.New("first"), fmt.Errorf("wrapped: %w", errors.New("second")))
+	types, messages := errorDetails(t, err)
+
+	wantTypes := []string{"*errors.joinError", "*errors.errorString", "*fmt.wrapError", "*errors.errorString"}
+	wantMessages := []string{"first\nwrapped: second", "first", "wrapped: second", "second"}
+	if fmt.Sprint(types) != fmt.Sprint(wantTypes) || fmt.Sprint(messages) != fmt.Sprint(wantMessages) {
+		t.Errorf("errors = %q %q, want %q %q in depth-first order", types, messages, wantTypes, wantMessages)
+	}
+}
+
+func TestErrorDetailsCycle(t *testing.T) {
+	self := &cycleError{msg: "self"}
+	self.next = self
+	a, b := &cycleError{msg: "a"}, &cycleError{msg: "b"}
+	a.next, b.next = b, a
+
+	for _, tt := range []struct {
+		err  error
+		want []string
+	}{
+		{self, []string{"self"}},
+		{a, []string{"a", "b"}},
+		{fmt.Errorf("wrapped: %w", errors.Join(a, self)), []string{"wrapped: a\nself", "a\nself", "a", "b", "self"}},
+	} {
+		if _, messages := errorDetails(t, tt.err); fmt.Sprint(messages) != fmt.Sprint(tt.want) {
+			t.Errorf("errors = %q, want each error of the cycle once: %q", messages, tt.want)
+		}
+	}
+
+	// the errors which cannot be told apart end at the limit
+	if types, _ := errorDetails(t, sliceError{"x"}); len(types) != 100 {
+		t.Errorf("got %d errors, want the walk stopped at 100", len(types))
+	}
+}
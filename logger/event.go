@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const defaultWebhookMaxAttempts = 5
+
+// WithTransitionLevel sets the level of the entries written by Logger.Transition.
+func WithTransitionLevel(level slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.transitionLevel = level
+	}
+}
+
+// WithWebhookMaxAttempts sets the number of attempts after which
+// Logger.WebhookDelivery treats a retryable failure as exhausted (default: 5).
+func WithWebhookMaxAttempts(n int) LoggerOption {
+	return func(l *Logger) {
+		l.webhookMaxAttempts = n
+	}
+}
+
+// Transition writes an entry describing a state transition of the entity.
+// The details are written in the "transition" group, so that log-based metrics can count
+// the transitions by entity, from, to and trigger.
+func (l *Logger) Transition(ctx context.Context, entity, from, to, trigger string, opts ...EntryOption) {
+	entry := NewEntry(l.transitionLevel, fmt.Sprintf("%s: %s -> %s", entity, from, to), opts...)
+	entry.additionalAttrs = append([]slog.Attr{
+		slog.Group("transition",
+			slog.String("entity", entity),
+			slog.String("from", from),
+			slog.String("to", to),
+			slog.String("trigger", trigger),
+		),
+	}, entry.additionalAttrs...)
+	l.write(ctx, entry)
+}
+
+// CacheAccess writes a debug entry describing a cache lookup.
+// The details are written in the "cache" group, where "hit" is numeric (1 or 0)
+// so that a log-based distribution metric on cache.hit directly gives the hit rate.
+func (l *Logger) CacheAccess(ctx context.Context, cache, key string, hit bool, opts ...EntryOption) {
+	msg, hitValue := "cache miss", 0
+	if hit {
+		msg, hitValue = "cache hit", 1
+	}
+	entry := NewEntry(LevelDebug, msg, opts...)
+	entry.additionalAttrs = append([]slog.Attr{
+		slog.Group("cache",
+			slog.String("name", cache),
+			slog.String("key", key),
+			slog.Int("hit", hitValue),
+		),
+	}, entry.additionalAttrs...)
+	l.write(ctx, entry)
+}
+
+// WebhookDelivery writes an entry describing an attempt to deliver a webhook.
+// The details are written in the "webhook" group. The entry is written at LevelInfo on 2xx,
+// at LevelWarning on a failure which will be retried, and at LevelError with the error report
+// on a failure which is not retryable or has exhausted the attempts.
+func (l *Logger) WebhookDelivery(ctx context.Context, url string, status int, attempt int, d time.Duration, err error, opts ...EntryOption) {
+	attrs := []slog.Attr{
+		slog.String("url", url),
+		slog.Int("status", status),
+		slog.Int("attempt", attempt),
+		slog.Float64("latency_ms", float64(d)/float64(time.Millisecond)),
+	}
+
+	var entry Entry
+	switch {
+	case err == nil && status >= 200 && status < 300:
+		entry = NewEntry(LevelInfo, fmt.Sprintf("webhook delivered to %s", url), opts...)
+	case isRetryable(status, err) && attempt < l.webhookMaxAttempts:
+		entry = NewEntry(LevelWarning, fmt.Sprintf("webhook delivery to %s failed: status %d, attempt %d", url, status, attempt), opts...)
+		if err != nil {
+			attrs = append(attrs, slog.String(KeyError, l.printErr(err)))
+		}
+	default:
+		if err == nil {
+			err = fmt.Errorf("webhook delivery to %s failed: status %d, attempt %d", url, status, attempt)
+		}
+		entry = l.errorEntry(LevelError, err, append([]EntryOption{WithErrorReport(true)}, opts...))
+	}
+	entry.additionalAttrs = append([]slog.Attr{slog.Group("webhook", anyAttrs(attrs)...)}, entry.additionalAttrs...)
+	l.write(ctx, entry)
+}
+
+// isRetryable reports whether the request is worth retrying judging from the status code.
+// A zero status with an error means that no response was received.
+func isRetryable(status int, err error) bool {
+	switch {
+	case status == 0:
+		return err != nil
+	case status == http.StatusRequestTimeout, status == http.StatusTooEarly, status == http.StatusTooManyRequests:
+		return true
+	default:
+		return status >= 500
+	}
+}
+
+func anyAttrs(attrs []slog.Attr) []any {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}
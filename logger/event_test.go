@@ -0,0 +1,41 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestWebhookDeliveryError(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithErrorLabeler(func(error) map[string]string {
+		return map[string]string{"kind": "webhook"}
+	}))
+	ctx := context.Background()
+
+	l.WebhookDelivery(ctx, "https://example.com", 0, 5, time.Second, errors.New("connection refused"))
+	l.WebhookDelivery(ctx, "https://example.com", 400, 1, time.Second, nil)
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []string{
+		"connection refused",
+		"webhook delivery to https://example.com failed: status 400, attempt 1",
+	} {
+		e := entries[i]
+		if e.Level != logger.LevelError || !e.ErrorReport || e.Message != want {
+			t.Errorf("entries[%d] = %+v, want the error report of %q", i, e, want)
+		}
+		if _, ok := e.Attrs[logger.KeyStackTrace]; !ok {
+			t.Errorf("entries[%d] has no stack trace", i)
+		}
+		if labels, _ := e.Attrs[logger.KeyLabels].(map[string]any); labels["kind"] != "webhook" {
+			t.Errorf("entries[%d] labels = %v, want those of the error", i, e.Attrs[logger.KeyLabels])
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// WithRetrySleep replaces the wait before each retry, e.g. to record the backoff without waiting.
+func WithRetrySleep(f func(ctx context.Context, d time.Duration) error) LoggerOption {
+	return func(l *Logger) {
+		l.sleep = f
+	}
+}
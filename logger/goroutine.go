@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"bytes"
+	"runtime"
+)
+
+// WithGoroutineID adds the "goroutine" attribute to every entry, which is the ID of the goroutine logging it,
+// to correlate the entries of the same goroutine while debugging. It is for debugging only,
+// since the ID is parsed from the header of runtime.Stack, which Go does not guarantee to keep.
+func WithGoroutineID(enabled bool) LoggerOption {
+	return func(l *Logger) {
+		l.goroutineID = enabled
+	}
+}
+
+const attrGoroutine = "goroutine"
+
+// goroutineID parses the header "goroutine 123 [running]:" of the stack. It returns 0 on failure.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b, ok := bytes.CutPrefix(b, []byte("goroutine "))
+	if !ok {
+		return 0
+	}
+	var id uint64
+	for _, c := range b {
+		if c < '0' || '9' < c {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+	return id
+}
@@ -0,0 +1,54 @@
+package logger_test
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+// currentGoroutineID reads the ID from the header of the stack, as the logger does.
+func currentGoroutineID(t *testing.T) float64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	id, err := strconv.Atoi(strings.Fields(string(buf))[1])
+	if err != nil {
+		t.Errorf("unexpected header of the stack: %q", buf) // not Fatalf, which must not be called on the other goroutines
+	}
+	return float64(id)
+}
+
+func TestGoroutineID(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithGoroutineID(true))
+	ctx := context.Background()
+
+	want := make([]float64, 2)
+	var wg sync.WaitGroup
+	for i := range want {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			want[i] = currentGoroutineID(t)
+			l.Info(ctx, strconv.Itoa(i))
+		}(i)
+		wg.Wait() // one by one to know the order of the entries
+	}
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, e := range entries {
+		if got := e.Attrs["goroutine"]; got != want[i] {
+			t.Errorf("goroutine of the entry %d = %v, want %v", i, got, want[i])
+		}
+	}
+	if want[0] == want[1] {
+		t.Errorf("both goroutines have the ID %v", want[0])
+	}
+}
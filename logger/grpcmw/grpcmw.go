@@ -0,0 +1,99 @@
+// Package grpcmw provides the gRPC server interceptors writing the request logs through the logger.
+package grpcmw
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns the interceptor which writes an entry for each unary RPC through l,
+// with the method, the status code, the latency and the peer.
+// The context of the handler carries the logger for the RPC given by Logger.ForRequest, which logger.From returns,
+// and the trace context of the incoming metadata for logger.WithTraceHeader.
+// The status codes are mapped to the severities by Level.
+func UnaryServerInterceptor(l *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, rl := requestContext(ctx, l)
+		resp, err := handler(ctx, req)
+		writeLog(ctx, rl, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns the interceptor which writes an entry for each streaming RPC through l
+// when the stream ends, as UnaryServerInterceptor does.
+func StreamServerInterceptor(l *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, rl := requestContext(ss.Context(), l)
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		writeLog(ctx, rl, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// Level maps the status code to the severity: LevelError for the codes indicating a fault of the server,
+// LevelWarning for those possibly caused by the server or the load, and LevelInfo for the others.
+func Level(code codes.Code) slog.Level {
+	switch code {
+	case codes.Unknown, codes.Unimplemented, codes.Internal, codes.DataLoss:
+		return logger.LevelError
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return logger.LevelWarning
+	default:
+		return logger.LevelInfo
+	}
+}
+
+// requestContext returns the context carrying the trace context of the metadata and the logger for the RPC.
+func requestContext(ctx context.Context, l *logger.Logger) (context.Context, *logger.Logger) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		h := make(http.Header, 2)
+		for _, key := range []string{"traceparent", "x-cloud-trace-context"} {
+			if v := md.Get(key); len(v) > 0 {
+				h.Set(key, v[0])
+			}
+		}
+		ctx = logger.ContextWithTraceHeader(ctx, h)
+	}
+	rl := l.ForRequest(ctx)
+	return logger.Into(ctx, rl), rl
+}
+
+func writeLog(ctx context.Context, l *logger.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		logger.Duration("latency_ms", time.Since(start)),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	opts := []logger.EntryOption{logger.WithAttrs(slog.Attr{Key: "grpc", Value: slog.GroupValue(attrs...)})}
+	if err != nil {
+		opts = append(opts, logger.WithError(err))
+	}
+	l.Custom(ctx, logger.NewEntry(Level(code), method+" "+code.String(), opts...))
+}
+
+// serverStream replaces the context of the stream with the one carrying the logger.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
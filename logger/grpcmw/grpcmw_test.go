@@ -0,0 +1,149 @@
+package grpcmw_test
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/grpcmw"
+	"github.com/ebi-yade/osuite/logger/logtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const method = "/osuite.test.v1.Service/Method"
+
+var codeTests = []struct {
+	code     codes.Code
+	severity slog.Level
+}{
+	{codes.OK, logger.LevelInfo},
+	{codes.NotFound, logger.LevelInfo},
+	{codes.Unavailable, logger.LevelWarning},
+	{codes.Internal, logger.LevelError},
+}
+
+// incomingContext returns the context of the RPC from the peer with the trace context.
+func incomingContext() context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	))
+	return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 50051}})
+}
+
+func handlerError(code codes.Code) error {
+	if code == codes.OK {
+		return nil
+	}
+	return status.Error(code, "failed")
+}
+
+func assertEntry(t *testing.T, c *logtest.Capture, code codes.Code, severity slog.Level) {
+	t.Helper()
+	entries := c.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want the one inside the handler and the request log", len(entries))
+	}
+	inside, e := entries[0], entries[1]
+	if inside.Message != "inside" {
+		t.Errorf("message = %q, want the one of logger.From inside the handler first", inside.Message)
+	}
+	if e.Level != severity {
+		t.Errorf("severity = %v, want %v", e.Level, severity)
+	}
+	if want := method + " " + code.String(); e.Message != want {
+		t.Errorf("message = %q, want %q", e.Message, want)
+	}
+	g, _ := e.Attrs["grpc"].(map[string]any)
+	if g["method"] != method || g["code"] != code.String() || g["peer"] != "192.0.2.1:50051" {
+		t.Errorf("grpc = %v, want the method, the code and the peer", g)
+	}
+	if _, ok := g["latency_ms"]; !ok {
+		t.Errorf("grpc = %v, want the latency", g)
+	}
+	wantTrace := "projects/" + logtest.ProjectID + "/traces/4bf92f3577b34da6a3ce929d0e0e4736"
+	for _, e := range entries {
+		if e.Trace != wantTrace || e.SpanID != "00f067aa0ba902b7" {
+			t.Errorf("%q: trace = %q, spanId = %q, want those of the metadata", e.Message, e.Trace, e.SpanID)
+		}
+	}
+	if _, ok := e.Attrs[logger.KeyError]; ok != (code != codes.OK) {
+		t.Errorf("error = %v, want it only for the failed RPC", e.Attrs[logger.KeyError])
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	for _, tt := range codeTests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			l, c := logtest.NewCapture(logger.WithTraceHeader())
+			intercept := grpcmw.UnaryServerInterceptor(l)
+			resp, err := intercept(incomingContext(), "req", &grpc.UnaryServerInfo{FullMethod: method},
+				func(ctx context.Context, req any) (any, error) {
+					logger.From(ctx).Info(ctx, "inside")
+					return "resp", handlerError(tt.code)
+				})
+			if resp != "resp" || status.Code(err) != tt.code {
+				t.Errorf("got (%v, %v), want those of the handler", resp, err)
+			}
+			assertEntry(t, c, tt.code, tt.severity)
+		})
+	}
+}
+
+// fakeStream is the grpc.ServerStream of the RPC given by incomingContext.
+type fakeStream struct {
+	grpc.ServerStream
+}
+
+func (fakeStream) Context() context.Context {
+	return incomingContext()
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	for _, tt := range codeTests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			l, c := logtest.NewCapture(logger.WithTraceHeader())
+			intercept := grpcmw.StreamServerInterceptor(l)
+			err := intercept(nil, fakeStream{}, &grpc.StreamServerInfo{FullMethod: method, IsServerStream: true},
+				func(srv any, ss grpc.ServerStream) error {
+					ctx := ss.Context()
+					logger.From(ctx).Info(ctx, "inside")
+					return handlerError(tt.code)
+				})
+			if status.Code(err) != tt.code {
+				t.Errorf("got %v, want the error of the handler", err)
+			}
+			assertEntry(t, c, tt.code, tt.severity)
+		})
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := map[codes.Code]slog.Level{
+		codes.OK:                 logger.LevelInfo,
+		codes.Canceled:           logger.LevelInfo,
+		codes.InvalidArgument:    logger.LevelInfo,
+		codes.Unauthenticated:    logger.LevelInfo,
+		codes.DeadlineExceeded:   logger.LevelWarning,
+		codes.PermissionDenied:   logger.LevelWarning,
+		codes.ResourceExhausted:  logger.LevelWarning,
+		codes.FailedPrecondition: logger.LevelWarning,
+		codes.Aborted:            logger.LevelWarning,
+		codes.OutOfRange:         logger.LevelWarning,
+		codes.Unavailable:        logger.LevelWarning,
+		codes.Unknown:            logger.LevelError,
+		codes.Unimplemented:      logger.LevelError,
+		codes.Internal:           logger.LevelError,
+		codes.DataLoss:           logger.LevelError,
+	}
+	for code, want := range tests {
+		if got := grpcmw.Level(code); got != want {
+			t.Errorf("Level(%v) = %v, want %v", code, got, want)
+		}
+	}
+}
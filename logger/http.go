@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+// WithHTTPRequest sets the httpRequest field of the entry.
+// It is skipped when req or req.Request is nil.
+// If req.RemoteIP is empty, the host part of req.Request.RemoteAddr is used instead.
+func WithHTTPRequest(req *logging.HTTPRequest) EntryOption {
+	return func(o *Entry) {
+		if req == nil || req.Request == nil {
+			return
+		}
+		o.httpRequest = req
+	}
+}
+
+// httpRequestValue renders logging.HTTPRequest in the JSON representation of the HttpRequest message.
+type httpRequestValue struct {
+	*logging.HTTPRequest
+}
+
+func (v httpRequestValue) LogValue() slog.Value {
+	req := v.Request
+	var url string
+	if req.URL != nil { // e.g. the request built by hand
+		u := *req.URL
+		u.Fragment, u.RawFragment = "", ""
+		url = u.String()
+	}
+	attrs := []slog.Attr{
+		slog.String("requestMethod", req.Method),
+		slog.String("requestUrl", url),
+		slog.String("protocol", req.Proto),
+	}
+	if v.RequestSize > 0 {
+		attrs = append(attrs, slog.String("requestSize", strconv.FormatInt(v.RequestSize, 10)))
+	}
+	if v.Status != 0 {
+		attrs = append(attrs, slog.Int("status", v.Status))
+	}
+	if v.ResponseSize > 0 {
+		attrs = append(attrs, slog.String("responseSize", strconv.FormatInt(v.ResponseSize, 10)))
+	}
+	if ua := req.UserAgent(); ua != "" {
+		attrs = append(attrs, slog.String("userAgent", ua))
+	}
+	if ip := v.remoteIP(); ip != "" {
+		attrs = append(attrs, slog.String("remoteIp", ip))
+	}
+	if v.LocalIP != "" {
+		attrs = append(attrs, slog.String("serverIp", v.LocalIP))
+	}
+	if ref := req.Referer(); ref != "" {
+		attrs = append(attrs, slog.String("referer", ref))
+	}
+	if v.Latency > 0 {
+		attrs = append(attrs, slog.String("latency", formatDuration(v.Latency)))
+	}
+	if v.CacheLookup {
+		attrs = append(attrs, slog.Bool("cacheLookup", true))
+	}
+	if v.CacheHit {
+		attrs = append(attrs, slog.Bool("cacheHit", true))
+	}
+	if v.CacheValidatedWithOriginServer {
+		attrs = append(attrs, slog.Bool("cacheValidatedWithOriginServer", true))
+	}
+	if v.CacheFillBytes > 0 {
+		attrs = append(attrs, slog.String("cacheFillBytes", strconv.FormatInt(v.CacheFillBytes, 10)))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (v httpRequestValue) remoteIP() string {
+	if v.RemoteIP != "" {
+		return v.RemoteIP
+	}
+	host, _, err := net.SplitHostPort(v.Request.RemoteAddr)
+	if err != nil {
+		return v.Request.RemoteAddr
+	}
+	return host
+}
+
+// formatDuration formats d as the JSON representation of google.protobuf.Duration (e.g. "1.234s").
+func formatDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
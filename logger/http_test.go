@@ -0,0 +1,66 @@
+package logger_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestWithHTTPRequest(t *testing.T) {
+	l, c := logtest.NewCapture()
+	ctx := context.Background()
+
+	r, err := http.NewRequest(http.MethodPost, "https://example.com/path?q=1#section", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.RemoteAddr = "192.0.2.1:1234"
+	r.Header.Set("User-Agent", "test")
+	l.Info(ctx, "request", logger.WithHTTPRequest(&logging.HTTPRequest{
+		Request:      r,
+		RequestSize:  10,
+		Status:       http.StatusCreated,
+		ResponseSize: 20,
+		Latency:      1500 * time.Millisecond,
+	}))
+	l.Info(ctx, "without URL", logger.WithHTTPRequest(&logging.HTTPRequest{
+		Request: &http.Request{Method: http.MethodGet},
+		Status:  http.StatusOK,
+	}))
+	l.Info(ctx, "without request", logger.WithHTTPRequest(&logging.HTTPRequest{Status: http.StatusOK}))
+
+	entries := c.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	req, _ := entries[0].Attrs[logger.KeyHTTPRequest].(map[string]any)
+	want := map[string]any{
+		"requestMethod": "POST",
+		"requestUrl":    "https://example.com/path?q=1",
+		"protocol":      "HTTP/1.1",
+		"requestSize":   "10",
+		"status":        float64(201),
+		"responseSize":  "20",
+		"userAgent":     "test",
+		"remoteIp":      "192.0.2.1",
+		"latency":       "1.5s",
+	}
+	for k, v := range want {
+		if req[k] != v {
+			t.Errorf("%s = %v, want %v", k, req[k], v)
+		}
+	}
+
+	req, _ = entries[1].Attrs[logger.KeyHTTPRequest].(map[string]any)
+	if req["requestUrl"] != "" || req["requestMethod"] != "GET" || req["status"] != float64(200) {
+		t.Errorf("httpRequest = %v, want the empty requestUrl for the request without URL", req)
+	}
+	if _, ok := entries[2].Attrs[logger.KeyHTTPRequest]; ok {
+		t.Errorf("httpRequest = %v, want it skipped without the request", entries[2].Attrs[logger.KeyHTTPRequest])
+	}
+}
@@ -0,0 +1,134 @@
+// Package httpmw provides the net/http middleware writing the access logs through the logger.
+package httpmw
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/ebi-yade/osuite/logger"
+)
+
+// Middleware writes an entry with the httpRequest field for each request.
+type Middleware struct {
+	logger *logger.Logger
+}
+
+// New returns the middleware writing the access logs through l.
+func New(l *logger.Logger) *Middleware {
+	return &Middleware{logger: l}
+}
+
+// Handler returns the handler which serves the request by next and then writes the access log.
+// The context of the request carries the logger for the request given by Logger.ForRequest, which logger.From returns,
+// and the trace context of the headers for logger.WithTraceHeader.
+// The entry is written at LevelInfo, LevelWarning for the status 4xx and LevelError for 5xx, without the error report.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx := logger.ContextWithTraceHeader(r.Context(), r.Header)
+		l := m.logger.ForRequest(ctx)
+		ctx = logger.Into(ctx, l)
+
+		rw := &responseWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		status := rw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		req := &logging.HTTPRequest{
+			Request:      r,
+			RequestSize:  r.ContentLength,
+			Status:       status,
+			ResponseSize: rw.size,
+			Latency:      time.Since(start),
+		}
+		msg := fmt.Sprintf("%s %s %d", r.Method, r.URL.Path, status)
+		l.Custom(ctx, logger.NewEntry(level(status), msg, logger.WithHTTPRequest(req)))
+	})
+}
+
+func level(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return logger.LevelError
+	case status >= 400:
+		return logger.LevelWarning
+	default:
+		return logger.LevelInfo
+	}
+}
+
+// responseWriter records the status and the size of the response.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 && status >= 200 { // not the informational responses
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher for the streaming handlers.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for the handlers taking over the connection, e.g. WebSocket.
+// The status is recorded as 101 Switching Protocols unless the header has been written.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("httpmw: %T does not implement http.Hijacker: %w", w.ResponseWriter, http.ErrNotSupported)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// ReadFrom implements io.ReaderFrom so that io.Copy keeps using sendfile of the original writer.
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	var n int64
+	var err error
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(w.ResponseWriter, r)
+	}
+	w.size += n
+	return n, err
+}
+
+// Unwrap returns the original writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,208 @@
+package httpmw_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/httpmw"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		status   float64
+		size     string
+		severity slog.Level
+	}{
+		{
+			name:     "implicit 200",
+			handler:  func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "hello") },
+			status:   200,
+			size:     "5",
+			severity: logger.LevelInfo,
+		},
+		{
+			name:     "no body",
+			handler:  func(w http.ResponseWriter, r *http.Request) {},
+			status:   200,
+			severity: logger.LevelInfo,
+		},
+		{
+			name: "4xx",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				io.WriteString(w, "not found")
+			},
+			status:   404,
+			size:     "9",
+			severity: logger.LevelWarning,
+		},
+		{
+			name:     "5xx",
+			handler:  func(w http.ResponseWriter, r *http.Request) { http.Error(w, "oops", http.StatusServiceUnavailable) },
+			status:   503,
+			size:     "5",
+			severity: logger.LevelError,
+		},
+		{
+			name: "informational response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusCreated)
+			},
+			status:   201,
+			severity: logger.LevelInfo,
+		},
+		{
+			name: "io.Copy",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(w, strings.NewReader("copied"))
+			},
+			status:   200,
+			size:     "6",
+			severity: logger.LevelInfo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, c := logtest.NewCapture()
+			h := httpmw.New(l).Handler(tt.handler)
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/path?q=1", nil))
+
+			entries := c.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.severity {
+				t.Errorf("severity = %v, want %v", e.Level, tt.severity)
+			}
+			if e.ErrorReport {
+				t.Error("the access log is written with the error report")
+			}
+			req, _ := e.Attrs[logger.KeyHTTPRequest].(map[string]any)
+			if req["status"] != tt.status {
+				t.Errorf("status = %v, want %v", req["status"], tt.status)
+			}
+			if size, _ := req["responseSize"].(string); size != tt.size {
+				t.Errorf("responseSize = %q, want %q", size, tt.size)
+			}
+			if req["requestMethod"] != http.MethodGet || req["requestUrl"] != "/path?q=1" {
+				t.Errorf("httpRequest = %v, want the method and the URL of the request", req)
+			}
+			if latency, _ := req["latency"].(string); !strings.HasSuffix(latency, "s") {
+				t.Errorf("latency = %q, want the duration", latency)
+			}
+		})
+	}
+}
+
+func TestHandlerContext(t *testing.T) {
+	l, c := logtest.NewCapture(logger.WithTraceHeader())
+	h := httpmw.New(l).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.From(r.Context()).Info(r.Context(), "inside")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := c.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want the one inside the handler and the access log", len(entries))
+	}
+	inside, access := entries[0], entries[1]
+	if inside.Message != "inside" {
+		t.Fatalf("message = %q, want the one inside the handler first", inside.Message)
+	}
+	wantTrace := "projects/" + logtest.ProjectID + "/traces/4bf92f3577b34da6a3ce929d0e0e4736"
+	for _, e := range entries {
+		if e.Trace != wantTrace || e.SpanID != "00f067aa0ba902b7" {
+			t.Errorf("%q: trace = %q, spanId = %q, want those of traceparent", e.Message, e.Trace, e.SpanID)
+		}
+	}
+	if id := inside.Attrs["requestId"]; id == nil || id != access.Attrs["requestId"] {
+		t.Errorf("requestId = %v and %v, want the same one of the request", id, access.Attrs["requestId"])
+	}
+}
+
+func TestHandlerHijack(t *testing.T) {
+	l, c := logtest.NewCapture()
+	srv := httptest.NewServer(httpmw.New(l).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Hijack() = %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		rw.Flush()
+	})))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("status = %d, want 101 by the hijacked connection", res.StatusCode)
+	}
+	srv.Close() // waits for the handler
+	entries := c.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if req, _ := entries[0].Attrs[logger.KeyHTTPRequest].(map[string]any); req["status"] != float64(101) {
+		t.Errorf("status = %v, want 101", req["status"])
+	}
+}
+
+func TestHandlerHijackNotSupported(t *testing.T) {
+	l, _ := logtest.NewCapture()
+	var err error
+	h := httpmw.New(l).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, err = w.(http.Hijacker).Hijack()
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Hijack() = %v, want http.ErrNotSupported for the writer without it", err)
+	}
+}
+
+func TestHandlerReadFrom(t *testing.T) {
+	l, c := logtest.NewCapture()
+	srv := httptest.NewServer(httpmw.New(l).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(io.ReaderFrom); !ok {
+			t.Error("the writer does not implement io.ReaderFrom")
+		}
+		io.Copy(w, strings.NewReader(strings.Repeat("x", 1000)))
+	})))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(bufio.NewReader(res.Body))
+	res.Body.Close()
+	srv.Close()
+	if len(body) != 1000 {
+		t.Errorf("got %d bytes, want 1000", len(body))
+	}
+	entries := c.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if req, _ := entries[0].Attrs[logger.KeyHTTPRequest].(map[string]any); req["responseSize"] != "1000" || req["status"] != float64(200) {
+		t.Errorf("httpRequest = %v, want the size copied by ReadFrom", req)
+	}
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// WithInsertIDKey derives the insertId of the entry from the key, so that the entries logged again with the same key,
+// e.g. by re-running a batch job, are deduplicated by Cloud Logging.
+// The key must be unique among the distinct entries, since the entries with the same insertId and timestamp are merged.
+func WithInsertIDKey(key string) EntryOption {
+	return func(o *Entry) {
+		o.insertIDKey = key
+	}
+}
+
+// WithContentInsertID derives the insertId of every entry from its content, i.e. the timestamp, the severity,
+// the message and the attributes in the canonical form of WithEntryChecksum, instead of generating a random one.
+// Note that the distinct entries with the identical content at the same nanosecond are merged by Cloud Logging;
+// add an attribute to tell them apart if it matters. WithInsertIDKey takes precedence over it.
+func WithContentInsertID() LoggerOption {
+	return func(l *Logger) {
+		l.contentInsertID = true
+	}
+}
+
+// insertIDNamespace is the namespace of the name-based UUIDs used as the insertIds.
+var insertIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/ebi-yade/osuite/logger#insertId"))
+
+func keyedInsertID(key string) string {
+	return uuid.NewSHA1(insertIDNamespace, []byte(key)).String()
+}
+
+// hashInsertID derives the insertId from the record and the attrs to be added to it.
+func (l *Logger) hashInsertID(r slog.Record, attrs []slog.Attr) string {
+	r = r.Clone()
+	r.AddAttrs(attrs...)
+	return keyedInsertID(digest(false, "", l.severity(r.Level), r, nil))
+}
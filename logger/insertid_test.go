@@ -0,0 +1,59 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestInsertIDKey(t *testing.T) {
+	l, capture := logtest.NewCapture()
+	ctx := context.Background()
+
+	l.Info(ctx, "first run", logger.WithInsertIDKey("job-1/record-1"))
+	l.Info(ctx, "second run", logger.WithInsertIDKey("job-1/record-1"))
+	l.Info(ctx, "other", logger.WithInsertIDKey("job-1/record-2"))
+
+	entries := capture.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].InsertID == "" || entries[0].InsertID != entries[1].InsertID {
+		t.Errorf("insertIds = %q, %q, want the same ones for the same key", entries[0].InsertID, entries[1].InsertID)
+	}
+	if entries[2].InsertID == entries[0].InsertID {
+		t.Errorf("insertId = %q, want another one for another key", entries[2].InsertID)
+	}
+}
+
+func TestContentInsertID(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var insertIDs []string
+	for run := 0; run < 2; run++ {
+		l, capture := logtest.NewCapture(logger.WithContentInsertID(), logger.WithClock(func() time.Time { return now }))
+		ctx := context.Background()
+
+		l.Info(ctx, "hello", logger.WithAttrs(slog.Int("n", 1)))
+		l.Info(ctx, "hello", logger.WithAttrs(slog.Int("n", 2)))
+		l.Info(ctx, "keyed", logger.WithInsertIDKey("key"))
+
+		for _, e := range capture.Entries() {
+			insertIDs = append(insertIDs, e.InsertID)
+		}
+	}
+	if len(insertIDs) != 6 {
+		t.Fatalf("got %d insertIds, want 6", len(insertIDs))
+	}
+	for i := 0; i < 3; i++ {
+		if insertIDs[i] != insertIDs[i+3] {
+			t.Errorf("insertIds of the entry %d = %q, %q, want the same ones across the runs", i, insertIDs[i], insertIDs[i+3])
+		}
+	}
+	if insertIDs[0] == insertIDs[1] {
+		t.Errorf("insertIds = %q, want distinct ones for distinct contents", insertIDs[:2])
+	}
+}
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// WithDefaultLabels sets the labels of every entry.
+// The labels set by WithLabels take precedence over them on the same key.
+func WithDefaultLabels(labels map[string]string) LoggerOption {
+	return func(l *Logger) {
+		if l.labels == nil {
+			l.labels = make(map[string]string, len(labels))
+		}
+		maps.Copy(l.labels, labels)
+	}
+}
+
+// WithLabels sets the labels of the entry.
+// Multiple calls are merged, and the later one takes precedence on the same key.
+func WithLabels(labels map[string]string) EntryOption {
+	return func(o *Entry) {
+		if o.labels == nil {
+			o.labels = make(map[string]string, len(labels))
+		}
+		maps.Copy(o.labels, labels)
+	}
+}
+
+// WithLabels returns a logger which adds the labels to every entry, over those of WithDefaultLabels
+// and the logger l on the same key. The returned logger shares everything else with l, and l is not modified.
+func (l *Logger) WithLabels(labels map[string]string) *Logger {
+	child := *l
+	child.labels = make(map[string]string, len(l.labels)+len(labels))
+	maps.Copy(child.labels, l.labels)
+	maps.Copy(child.labels, labels)
+	return &child
+}
+
+type contextLabelsKey struct{}
+
+// ContextWithLabels returns a copy of ctx carrying the labels, which are added to every entry logged with it,
+// e.g. the tenant of the request. They take precedence over the labels of the logger,
+// and those of WithLabels take precedence over them. The labels are merged with those already carried by ctx,
+// and the new one replaces the old one on the same key.
+func ContextWithLabels(ctx context.Context, labels map[string]string) context.Context {
+	parent := labelsFromContext(ctx)
+	merged := make(map[string]string, len(parent)+len(labels))
+	maps.Copy(merged, parent)
+	maps.Copy(merged, labels)
+	return context.WithValue(ctx, contextLabelsKey{}, merged)
+}
+
+func labelsFromContext(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(contextLabelsKey{}).(map[string]string)
+	return labels
+}
+
+// labelsAttr builds the labels field from the logger, the context and the entry.
+// It returns false if there is no label.
+func (l *Logger) labelsAttr(ctx context.Context, entry Entry) (slog.Attr, bool) {
+	ctxLabels := labelsFromContext(ctx)
+	merged := make(map[string]string, len(l.labels)+len(ctxLabels)+len(entry.labels)+1)
+	maps.Copy(merged, l.labels)
+	if l.environment != "" {
+		merged["environment"] = l.environment
+	}
+	maps.Copy(merged, ctxLabels)
+	maps.Copy(merged, entry.labels)
+	if len(merged) == 0 {
+		return slog.Attr{}, false
+	}
+
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	attrs := make([]any, len(keys))
+	for i, k := range keys {
+		attrs[i] = slog.String(k, merged[k])
+	}
+	return slog.Group(KeyLabels, attrs...), true
+}
+
+const labelComponent = "component"
+
+// WithDefaultComponent sets the "component" label of every entry,
+// which identifies the subsystem logging the entry independently of the source location.
+func WithDefaultComponent(component string) LoggerOption {
+	return WithDefaultLabels(map[string]string{labelComponent: component})
+}
+
+// WithComponent sets the "component" label of the entry, overriding the one set by WithDefaultComponent.
+func WithComponent(component string) EntryOption {
+	return WithLabels(map[string]string{labelComponent: component})
+}
+
+// WithErrorLabeler sets the function to derive the labels from the error of the error methods such as Logger.Error,
+// e.g. to label the entries with the code of the domain errors. f receives the error as it is given to the method,
+// so use errors.As in f to find a wrapped one. The labels set by WithLabels take precedence on the same key.
+func WithErrorLabeler(f func(error) map[string]string) LoggerOption {
+	return func(l *Logger) {
+		l.errorLabeler = f
+	}
+}
+
+// applyErrorLabels merges the labels derived from the error into the entry.
+func (l *Logger) applyErrorLabels(entry *Entry) {
+	if l.errorLabeler == nil || entry.err == nil {
+		return
+	}
+	labels := l.errorLabeler(entry.err)
+	if len(labels) == 0 {
+		return
+	}
+	if entry.labels == nil {
+		entry.labels = make(map[string]string, len(labels))
+	}
+	for k, v := range labels {
+		if _, ok := entry.labels[k]; !ok {
+			entry.labels[k] = v
+		}
+	}
+}
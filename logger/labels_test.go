@@ -0,0 +1,74 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+// codeError is the domain error carrying the code.
+type codeError struct{ code string }
+
+func (e *codeError) Error() string { return "code " + e.code }
+func (e *codeError) Code() string  { return e.code }
+
+func TestErrorLabeler(t *testing.T) {
+	var given []error
+	l, capture := logtest.NewCapture(logger.WithErrorLabeler(func(err error) map[string]string {
+		given = append(given, err)
+		var ce *codeError
+		if !errors.As(err, &ce) {
+			return nil
+		}
+		return map[string]string{"error_code": ce.Code(), "env": "labeler"}
+	}))
+	ctx := context.Background()
+	err := fmt.Errorf("wrapped: %w", &codeError{code: "E42"})
+
+	l.Error(ctx, err, logger.WithLabels(map[string]string{"env": "entry"}))
+	l.Error(ctx, errors.New("plain"))
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	labels, _ := entries[0].Attrs[logger.KeyLabels].(map[string]any)
+	if labels["error_code"] != "E42" || labels["env"] != "entry" {
+		t.Errorf("labels = %v, want the code of the error and the env of the entry", labels)
+	}
+	if _, ok := entries[1].Attrs[logger.KeyLabels]; ok {
+		t.Errorf("labels = %v, want none for the error without a code", entries[1].Attrs[logger.KeyLabels])
+	}
+	if len(given) != 2 || given[0] != err {
+		t.Errorf("the labeler is given %v, want the original errors", given)
+	}
+}
+
+func TestComponent(t *testing.T) {
+	ctx := context.Background()
+	l, capture := logtest.NewCapture(logger.WithDefaultComponent("billing"), logger.WithDefaultLabels(map[string]string{"team": "core"}))
+	l.Info(ctx, "default")
+	l.Info(ctx, "entry", logger.WithComponent("invoice"))
+	l.Info(ctx, "labels", logger.WithLabels(map[string]string{"component": "labels"}), logger.WithComponent("invoice"))
+
+	entries := capture.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, want := range []string{"billing", "invoice", "invoice"} {
+		labels, _ := entries[i].Attrs[logger.KeyLabels].(map[string]any)
+		if labels["component"] != want || labels["team"] != "core" {
+			t.Errorf("%q: labels = %v, want the component %q with the default labels", entries[i].Message, labels, want)
+		}
+	}
+
+	l, capture = logtest.NewCapture()
+	l.Info(ctx, "without default")
+	if labels, ok := capture.Entries()[0].Attrs[logger.KeyLabels]; ok {
+		t.Errorf("labels = %v, want no component label without WithDefaultComponent", labels)
+	}
+}
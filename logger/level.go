@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// ParseLevel parses the name of the severity, such as "DEBUG" or "WARNING", case-insensitively.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToUpper(s) {
+	case "DEFAULT":
+		return LevelDefault, nil
+	case "DEBUG":
+		return LevelDebug, nil
+	case "INFO":
+		return LevelInfo, nil
+	case "NOTICE":
+		return LevelNotice, nil
+	case "WARNING":
+		return LevelWarning, nil
+	case "ERROR":
+		return LevelError, nil
+	case "CRITICAL":
+		return LevelCritical, nil
+	case "ALERT":
+		return LevelAlert, nil
+	case "EMERGENCY":
+		return LevelEmergency, nil
+	default:
+		return 0, fmt.Errorf("unknown severity %q: must be one of DEFAULT, DEBUG, INFO, NOTICE, WARNING, ERROR, CRITICAL, ALERT or EMERGENCY", s)
+	}
+}
@@ -1,16 +1,20 @@
-package main
+package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"runtime"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
 	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/apiv2/loggingpb"
 	"github.com/google/uuid"
 )
 
@@ -26,32 +30,122 @@ var (
 	LevelEmergency = slog.Level(logging.Emergency)
 
 	logAttrReporting = slog.String(
-		"@type",
+		KeyType,
 		"type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent",
 	)
 )
 
+// The keys of the fields in the emitted entries, which are recognized by Cloud Logging
+// or used by this package. The attributes of the user should not use them at the top level.
 const (
-	logMessageKey        = "message"
-	logSeverityKey       = "severity"
-	logSourceLocationKey = "logging.googleapis.com/sourceLocation"
-	logTraceKey          = "logging.googleapis.com/trace"
-	logSpanIDKey         = "logging.googleapis.com/spanId"
-	logInsertIDKey       = "logging.googleapis.com/insertId"
+	KeyTime           = slog.TimeKey
+	KeySeverity       = "severity"
+	KeyMessage        = "message"
+	KeySourceLocation = "logging.googleapis.com/sourceLocation"
+	KeyTrace          = "logging.googleapis.com/trace"
+	KeySpanID         = "logging.googleapis.com/spanId"
+	KeyTraceSampled   = "logging.googleapis.com/trace_sampled"
+	KeyInsertID       = "logging.googleapis.com/insertId"
+	KeyLabels         = "logging.googleapis.com/labels"
+	KeyOperation      = "logging.googleapis.com/operation"
+	KeyHTTPRequest    = "httpRequest"
+	KeyType           = "@type"
+	KeyServiceContext = "serviceContext"
+	KeyContext        = "context"
+	KeyStackTrace     = "stack_trace"
+	KeyChecksum       = "checksum"
+	KeyError          = "error"
+	KeyErrors         = "errors"
 )
 
+// Logger writes structured entries in the format of Cloud Logging.
+//
+// A Logger is safe for concurrent use by multiple goroutines, and so are the loggers derived by With.
+// The entries are passed to the handler one at a time, so each entry is written to the io.Writer
+// by a single Write call and never concurrently with another entry, even if the writer itself is not safe for concurrent use.
 type Logger struct {
-	handler   slog.Handler
-	projectID string
+	handler       slog.Handler
+	level         *slog.LevelVar
+	projectID     string
+	checksum      *checksummer
+	aggregator    *aggregator
+	limiter       *rateLimiter
+	async         *asyncWriter
+	split         *splitWriters
+	cloudLogging  *cloudLogging
+	writer        *swapWriter
+	emitMu        *sync.Mutex // serializes the handler across the goroutines
+	spanIDWarning *sync.Once
+	lifecycle     *lifecycle
+
+	name            string
+	attrs           []slog.Attr
+	goas            []groupOrAttrs // the groups of WithGroup and the attributes of With in them
+	labels          map[string]string
+	environment     string
+	stripANSI       bool
+	console         bool
+	skipCaller      int
+	noSource        bool
+	messageKey      string
+	payloadGroup    string
+	goroutineID     bool
+	contentInsertID bool
+	errorDetails    bool
+
+	service            string
+	version            string
+	transitionLevel    slog.Level
+	webhookMaxAttempts int
+	retry              RetryPolicy
+	sleep              func(ctx context.Context, d time.Duration) error // waits before each retry
+	stackDepth         int
+	maxStringBytes     int
+	errorReportLevel   slog.Level
+	writeTimeout       time.Duration
+	contextErrorPolicy *contextErrorPolicy
+	serverTimestamp    bool
+	operationProducer  string
 
 	// dependency injection
-	printErr   func(error) string
-	getTraceID func(context.Context) string
-	getSpanID  func(context.Context) string
+	now              func() time.Time
+	newInsertID      func(context.Context) string
+	printErr         func(error) string
+	formatPanic      func(any) string
+	severity         func(slog.Level) logging.Severity
+	errorLabeler     func(error) map[string]string
+	getTraceID       func(context.Context) string
+	getSpanID        func(context.Context) string
+	getTraceSampled  func(context.Context) bool
+	getCorrelationID func(context.Context) string
+	minLevel         func(context.Context) slog.Level
+	sampler          func(slog.Level) bool
+	onDrop           func(context.Context, Entry, DropReason)
+	onEntry          func(context.Context, Entry)
+	metrics          func(slog.Level)
+	skipCanceled     bool
+	redactor         func([]string, slog.Attr) slog.Attr
+	onError          func(context.Context, error)
+	exit             func(code int)
 }
 
 type LoggerOption func(*Logger)
 
+// WithClock sets the function to get the current time, which is time.Now by default.
+func WithClock(f func() time.Time) LoggerOption {
+	return func(l *Logger) {
+		l.now = f
+	}
+}
+
+// WithInsertIDFunc sets the function to generate the insertId, which is uuid.NewString by default.
+// The insertId must be unique among the entries with the same timestamp.
+func WithInsertIDFunc(f func(context.Context) string) LoggerOption {
+	return func(l *Logger) {
+		l.newInsertID = f
+	}
+}
+
 // WithPrintError sets the function to print error.
 func WithPrintError(f func(error) string) LoggerOption {
 	return func(l *Logger) {
@@ -73,37 +167,245 @@ func WithSpanID(f func(context.Context) string) LoggerOption {
 	}
 }
 
+// WithTraceSampled sets the function to get whether the trace is sampled from context.
+// The flag is emitted only when the entry has a traceID.
+func WithTraceSampled(f func(context.Context) bool) LoggerOption {
+	return func(l *Logger) {
+		l.getTraceSampled = f
+	}
+}
+
+// WithFallbackCorrelationID sets the function to get the ID correlating the entries from context,
+// e.g. the ID from the header of the incoming request, for the services not traced by Cloud Trace.
+// The ID is emitted as the "correlation_id" attribute only when the entry has no traceID.
+func WithFallbackCorrelationID(f func(context.Context) string) LoggerOption {
+	return func(l *Logger) {
+		l.getCorrelationID = f
+	}
+}
+
+const attrCorrelationID = "correlation_id"
+
+// WithExitFunc sets the function called by Logger.Fatal, which is os.Exit by default.
+func WithExitFunc(f func(code int)) LoggerOption {
+	return func(l *Logger) {
+		l.exit = f
+	}
+}
+
+// WithMinLevelFunc sets the function to decide the minimum level for each call from the context,
+// e.g. to enable the debug entries only for the requests with a debug flag.
+// It takes the place of the minimum level of the logger, including SetLevel.
+func WithMinLevelFunc(f func(context.Context) slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.minLevel = f
+	}
+}
+
+// Enabled reports whether the entries at the level are written, following SetLevel and WithMinLevelFunc.
+// Use it to skip building the expensive attributes, e.g. `if l.Enabled(ctx, LevelDebug) { ... }`.
+// The entries may still be dropped by the sampler and the other filters applied when writing.
+func (l *Logger) Enabled(ctx context.Context, level slog.Level) bool {
+	if l.minLevel != nil {
+		return level >= l.minLevel(ctx)
+	}
+	return l.handler.Enabled(ctx, level)
+}
+
+// WithDefaultSkipCaller sets the number of stack frames to skip when getting the caller of every entry,
+// e.g. 1 for the logger wrapped by a helper function, so that the source location points at the caller of the helper.
+func WithDefaultSkipCaller(skip int) LoggerOption {
+	return func(l *Logger) {
+		l.skipCaller = skip
+	}
+}
+
+// WithSource sets whether the source location is added to the entries, which is true by default.
+// Disabling it saves the cost of runtime.Callers in hot paths, but the entries reported to Error Reporting
+// keep the source location regardless, since it is essential to locate the error.
+func WithSource(enabled bool) LoggerOption {
+	return func(l *Logger) {
+		l.noSource = !enabled
+	}
+}
+
+// needsSource reports whether the source location of the entry should be captured.
+// The entries reported to Error Reporting always need it, even if it is disabled by WithSource.
+func (l *Logger) needsSource(entry Entry) bool {
+	return !l.noSource || entry.errorReport
+}
+
+// WithDefaultStackDepth sets the maximum number of frames in the stack traces, which is 32 by default.
+// The frames beyond it are summarized as "... N more frames". 0 disables the stack traces.
+func WithDefaultStackDepth(depth int) LoggerOption {
+	return func(l *Logger) {
+		l.stackDepth = depth
+	}
+}
+
+// WithServiceContext sets the serviceContext of the entries reported as errors.
+// By default, the service and the version are read from K_SERVICE and K_REVISION set by Cloud Run.
+func WithServiceContext(service, version string) LoggerOption {
+	return func(l *Logger) {
+		l.service = service
+		l.version = version
+	}
+}
+
+// WithConsoleFormat makes the logger write human-readable lines instead of JSON, for local development.
+// The lines are colored by the severity unless the writer is not a terminal or NO_COLOR is set.
+// It has no effect on the loggers of NewWithClient and WithCloudLogging.
+func WithConsoleFormat() LoggerOption {
+	return func(l *Logger) {
+		l.console = true
+	}
+}
+
+// WithProjectID sets the projectID used to format the traceID.
+func WithProjectID(projectID string) LoggerOption {
+	return func(l *Logger) {
+		l.projectID = projectID
+	}
+}
+
+// WithSeverityMapper sets the function to map the level of each entry to the severity,
+// e.g. to map the custom levels between the predefined ones deterministically.
+// By default, the level is converted to logging.Severity as is.
+func WithSeverityMapper(f func(slog.Level) logging.Severity) LoggerOption {
+	return func(l *Logger) {
+		l.severity = f
+	}
+}
+
+// WithMessageKey sets the key of the message, which is KeyMessage by default.
+// Note that Cloud Logging shows the message as the summary of the entry only when the key is KeyMessage.
+func WithMessageKey(key string) LoggerOption {
+	return func(l *Logger) {
+		l.messageKey = key
+	}
+}
+
+// WithPayloadGroup puts the attributes of the entries under the group of the name,
+// while the fields of Cloud Logging such as the severity, the message and the trace stay at the top level.
+func WithPayloadGroup(name string) LoggerOption {
+	return func(l *Logger) {
+		l.payloadGroup = name
+	}
+}
+
+// WithEnvironment sets the "environment" label of every entry.
+// If env is empty, the value of ENV or APP_ENV is used instead.
+func WithEnvironment(env string) LoggerOption {
+	return func(l *Logger) {
+		if env == "" {
+			env = os.Getenv("ENV")
+		}
+		if env == "" {
+			env = os.Getenv("APP_ENV")
+		}
+		l.environment = env
+	}
+}
+
+// Default returns the default logger writing to os.Stderr.
+// The minimum level is read from LOG_LEVEL, and it falls back to LevelDefault when unset or invalid.
+// It returns an error if GOOGLE_CLOUD_PROJECT is not set.
+var Default = sync.OnceValues(func() (*Logger, error) {
+	projectID := os.Getenv("GOOGLE_CLOUD_PROJECT")
+	if projectID == "" {
+		return nil, errors.New("GOOGLE_CLOUD_PROJECT is not set")
+	}
+	minLevel, err := ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		minLevel = LevelDefault
+	}
+	return New(os.Stderr, projectID, minLevel), nil
+})
+
 // MustDefault returns the default logger.
 // Note: This method panics if GOOGLE_CLOUD_PROJECT is not set.
 var MustDefault = sync.OnceValue(func() *Logger {
-	projectID := os.Getenv("GOOGLE_CLOUD_PROJECT")
-	if projectID == "" {
-		panic("GOOGLE_CLOUD_PROJECT is not set")
+	logger, err := Default()
+	if err != nil {
+		panic(err)
 	}
-	return New(os.Stderr, projectID, slog.Level(logging.Default))
+	return logger
 })
 
+// New returns the logger writing the entries to w as JSON lines.
+// w does not need to be safe for concurrent use (see Logger).
 func New(w io.Writer, projectID string, minLevel slog.Level, opts ...LoggerOption) *Logger {
-	replaceAttr := func(groups []string, a slog.Attr) slog.Attr {
-		switch a.Key {
-		case slog.LevelKey:
-			return slog.String(logSeverityKey, logging.Severity(a.Value.Any().(slog.Level)).String())
-		case slog.SourceKey:
-			a.Key = logSourceLocationKey
-		case slog.MessageKey:
-			a.Key = logMessageKey
-		}
-		return a
+	logger := newLogger(projectID, minLevel, opts...)
+	if logger.handler != nil {
+		return logger // set by WithHandler
+	}
+	if c := logger.cloudLogging; c != nil {
+		logger.useClient(c.client, c.logID)
+		return logger
 	}
-	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: minLevel, ReplaceAttr: replaceAttr})
+	if s := logger.split; s != nil {
+		logger.handler = &splitHandler{low: logger.newHandler(s.out), high: logger.newHandler(s.err), threshold: s.threshold}
+		logger.lifecycle.closeSink = s.flush
+		return logger
+	}
+	logger.writer = newSwapWriter(w)
+	logger.handler = logger.newHandler(logger.writer)
+	logger.lifecycle.closeSink = logger.writer.Flush
+	return logger
+}
+
+// WithHandler makes New use the handler instead of writing to the io.Writer, which is ignored then.
+// The records passed to the handler have the attributes of Cloud Logging such as the trace and the insertId,
+// but the built-in keys of log/slog are not rewritten (e.g. "level" instead of "severity"),
+// and the minimum level of the logger including SetLevel has no effect since the handler decides it.
+func WithHandler(h slog.Handler) LoggerOption {
+	return func(l *Logger) {
+		l.handler = h
+	}
+}
+
+// Handler returns the underlying handler, which writes the records as they are built by the logger.
+// Writing to it directly bypasses the features of the logger which work before the handler, e.g. WithAsync.
+func (l *Logger) Handler() slog.Handler {
+	return l.handler
+}
+
+// newLogger returns the logger with the options applied, whose handler is set by the caller.
+func newLogger(projectID string, minLevel slog.Level, opts ...LoggerOption) *Logger {
+	level := new(slog.LevelVar)
+	level.Set(minLevel)
 
 	// default
 	logger := &Logger{
-		handler:   handler,
-		projectID: projectID,
+		level:              level,
+		projectID:          projectID,
+		lifecycle:          &lifecycle{},
+		emitMu:             &sync.Mutex{},
+		spanIDWarning:      &sync.Once{},
+		messageKey:         KeyMessage,
+		service:            os.Getenv("K_SERVICE"),
+		version:            os.Getenv("K_REVISION"),
+		transitionLevel:    LevelInfo,
+		webhookMaxAttempts: defaultWebhookMaxAttempts,
+		stackDepth:         defaultStackDepth,
+		maxStringBytes:     defaultMaxStringBytes,
+		errorReportLevel:   LevelError,
+		now:                time.Now,
+		sleep:              sleep,
+		exit:               os.Exit,
+		newInsertID: func(ctx context.Context) string {
+			return uuid.NewString()
+		},
+		formatPanic: func(v any) string {
+			return fmt.Sprintf("%v", v)
+		},
 		printErr: func(err error) string {
 			return fmt.Sprintf("%+v", err) // expected errors are wrapped by pkg/errors
 		},
+		onError: printHandlerError,
+		severity: func(level slog.Level) logging.Severity {
+			return logging.Severity(level)
+		},
 		getTraceID: func(ctx context.Context) string {
 			return ""
 		},
@@ -118,6 +420,151 @@ func New(w io.Writer, projectID string, minLevel slog.Level, opts ...LoggerOptio
 	return logger
 }
 
+// newHandler returns the handler writing the entries to w in the format configured by the options.
+func (l *Logger) newHandler(w io.Writer) slog.Handler {
+	if l.console {
+		return newConsoleHandler(w, l.level, l.redact, l.severity)
+	}
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: l.level, ReplaceAttr: l.replaceAttr})
+}
+
+// replaceAttr rewrites the built-in attributes of log/slog to the special fields of Cloud Logging.
+func (l *Logger) replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 && groups[0] == KeyLabels {
+		return a // label keys are arbitrary and must be kept as they are
+	}
+	if len(groups) > 0 {
+		return l.redact(groups, a) // the built-in keys are only at the top level; nested ones are user data
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		if a.Value.Kind() == slog.KindTime {
+			return slog.String(KeyTime, a.Value.Time().UTC().Format(timeLayout))
+		}
+	case slog.LevelKey:
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			return slog.String(KeySeverity, l.severity(level).String())
+		}
+	case slog.SourceKey:
+		if src, ok := a.Value.Any().(*slog.Source); ok {
+			return sourceLocationAttr(src)
+		}
+	case slog.MessageKey:
+		a.Key = l.messageKey
+		return a
+	}
+	return l.redact(groups, a)
+}
+
+// timeLayout is RFC3339Nano in UTC with the fixed number of fractional digits,
+// so that the timestamps keep the nanoseconds and sort lexically.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// sourceLocationAttr formats the source in the LogEntrySourceLocation schema, where the line is a string.
+func sourceLocationAttr(src *slog.Source) slog.Attr {
+	if src == nil || src.File == "" {
+		return slog.Attr{}
+	}
+	return slog.Group(KeySourceLocation,
+		slog.String("file", src.File),
+		slog.String("line", strconv.Itoa(src.Line)),
+		slog.String("function", src.Function),
+	)
+}
+
+func (l *Logger) serviceContextAttr() slog.Attr {
+	attrs := []any{slog.String("service", l.service)}
+	if l.version != "" {
+		attrs = append(attrs, slog.String("version", l.version))
+	}
+	return slog.Group(KeyServiceContext, attrs...)
+}
+
+// reportLocationValue formats the caller as context.reportLocation of ReportedErrorEvent,
+// which Error Reporting requires for the entries without a stack trace, e.g. a warning reported by WithErrorReport.
+// The caller is symbolized lazily by the handler.
+type reportLocationValue struct {
+	pc uintptr
+}
+
+func (v reportLocationValue) LogValue() slog.Value {
+	frame, _ := runtime.CallersFrames([]uintptr{v.pc}).Next()
+	return slog.GroupValue(slog.Group("reportLocation",
+		slog.String("filePath", frame.File),
+		slog.Int("lineNumber", frame.Line),
+		slog.String("functionName", frame.Function),
+	))
+}
+
+// SetLevel changes the minimum level of the logger at runtime.
+// The change is shared by the loggers derived from the same logger.
+func (l *Logger) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// Level returns the current minimum level of the logger.
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// With returns a logger which adds the attributes to every entry, before the attributes of the entry.
+// After WithGroup, the attributes are put in the group.
+// The returned logger shares everything else with l, and l is not modified.
+func (l *Logger) With(attrs ...slog.Attr) *Logger {
+	if len(attrs) == 0 {
+		return l
+	}
+	child := *l
+	if len(l.goas) > 0 {
+		child.goas = append(slices.Clip(l.goas), groupOrAttrs{attrs: slices.Clone(attrs)})
+	} else {
+		child.attrs = append(slices.Clip(l.attrs), attrs...)
+	}
+	return &child
+}
+
+// WithGroup returns a logger which puts the attributes of the later With, ContextWithAttrs and the entries
+// in the group, as slog.Logger.WithGroup does. The group is omitted from the entries without such attributes.
+// An empty name returns l. The returned logger shares everything else with l, and l is not modified.
+func (l *Logger) WithGroup(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	child := *l
+	child.goas = append(slices.Clip(l.goas), groupOrAttrs{group: name})
+	return &child
+}
+
+// ForRequest returns a logger for the request of ctx, which adds a newly generated "requestId" attribute
+// to every entry. The trace, the span and the sampling decision are taken from ctx at this point,
+// so the entries logged by the returned logger carry them regardless of the context given to each call.
+// l is not modified.
+func (l *Logger) ForRequest(ctx context.Context) *Logger {
+	child := l.With(slog.String(attrRequestID, uuid.NewString()))
+	traceID, spanID := l.getTraceID(ctx), l.getSpanID(ctx)
+	sampled := l.getTraceSampled != nil && l.getTraceSampled(ctx)
+	child.getTraceID = func(context.Context) string { return traceID }
+	child.getSpanID = func(context.Context) string { return spanID }
+	child.getTraceSampled = func(context.Context) bool { return sampled }
+	return child
+}
+
+const attrRequestID = "requestId"
+
+// Named returns a logger which adds the "logger" attribute to every entry, before the attributes of With.
+// The names are joined by "." when Named is called repeatedly, e.g. "db.pool" for Named("db").Named("pool").
+// The returned logger shares everything else with l, and l is not modified.
+func (l *Logger) Named(name string) *Logger {
+	child := *l
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	child.name = name
+	return &child
+}
+
+const attrLogger = "logger"
+
 type EntryOption func(*Entry)
 
 type Entry struct {
@@ -126,9 +573,26 @@ type Entry struct {
 	additionalAttrs []slog.Attr
 	skipCaller      int
 	errorReport     bool
+	httpRequest     *logging.HTTPRequest
+	operation       *loggingpb.LogEntryOperation
+	labels          map[string]string
+	err             error
+	errorAttr       error
+	formatErr       func(error) string
+	rawFields       []slog.Attr
+	insertIDKey     string
+	stackTrace      bool
+	stackDepth      int
+	hasStackDepth   bool
+	stack           []uintptr
+	time            time.Time // the time of the record given to the slog.Handler; zero means the time of logging
 }
 
 func NewEntry(level slog.Level, msg string, opts ...EntryOption) Entry {
+	if len(opts) == 0 {
+		// not to move params to the heap by the options below, e.g. for the entries dropped by the level
+		return Entry{level: level, msg: msg}
+	}
 	params := Entry{
 		level: level,
 		msg:   msg,
@@ -139,63 +603,474 @@ func NewEntry(level slog.Level, msg string, opts ...EntryOption) Entry {
 	return params
 }
 
-// WithAttrs sets the attributes of the entry.
+// Level returns the level of the entry.
+func (e Entry) Level() slog.Level {
+	return e.level
+}
+
+// Message returns the message of the entry.
+func (e Entry) Message() string {
+	return e.msg
+}
+
+// Attrs returns a copy of the attributes of the entry, i.e. those given by WithAttrs and AddAttrs.
+func (e Entry) Attrs() []slog.Attr {
+	return slices.Clone(e.additionalAttrs)
+}
+
+// Err returns the error of the entry given to the error methods such as Logger.Error, or nil.
+func (e Entry) Err() error {
+	return e.err
+}
+
+// IsErrorReport reports whether the entry is reported as an error.
+func (e Entry) IsErrorReport() bool {
+	return e.errorReport
+}
+
+// AddAttrs appends the attributes to the entry, e.g. to decorate the entry before passing it to Logger.Custom.
+func (e *Entry) AddAttrs(attrs ...slog.Attr) {
+	e.additionalAttrs = append(slices.Clip(e.additionalAttrs), attrs...) // not to share the array with the copies
+}
+
+// WithAttrs adds the attributes to the entry.
+// Multiple calls accumulate the attributes in order.
+// A top-level attribute named after a built-in field of log/slog such as "msg" or a field of the entry such as "severity"
+// is emitted with the "user_" prefix.
 func WithAttrs(attrs ...slog.Attr) EntryOption {
 	return func(o *Entry) {
-		o.additionalAttrs = attrs
+		o.additionalAttrs = append(o.additionalAttrs, attrs...)
 	}
 }
 
-// WithSkipCaller sets the number of stack frames to skip when getting the caller.
+// WithSkipCaller sets the number of stack frames to skip when getting the caller,
+// in addition to the default of the logger set by WithDefaultSkipCaller.
 func WithSkipCaller(skip int) EntryOption {
 	return func(o *Entry) {
 		o.skipCaller = skip
 	}
 }
 
+// WithError attaches the error formatted by the logger as the "error" attribute,
+// without reporting the entry as an error. It is useful to log a handled error at a lower severity.
+func WithError(err error) EntryOption {
+	return func(o *Entry) {
+		o.errorAttr = err
+	}
+}
+
+// WithRawField adds the top-level field to the entry as it is, e.g. a special field of Cloud Logging
+// which this package does not support such as "appengine.googleapis.com/request_log_id".
+// Unlike WithAttrs, it is never put in the group of WithPayloadGroup nor deduplicated with the attributes.
+// The caller is responsible for the key and the shape of the value.
+func WithRawField(key string, value any) EntryOption {
+	return func(o *Entry) {
+		o.rawFields = append(o.rawFields, slog.Any(key, value))
+	}
+}
+
+// WithErrorFormatter sets the function to format the error of the entry,
+// overriding the one of the logger set by WithPrintError.
+func WithErrorFormatter(f func(error) string) EntryOption {
+	return func(o *Entry) {
+		o.formatErr = f
+	}
+}
+
+// formatErr formats the error with the formatter of the entry if any, or with the one of the logger.
+func (l *Logger) formatErr(entry Entry, err error) string {
+	if entry.formatErr != nil {
+		return entry.formatErr(err)
+	}
+	return l.printErr(err)
+}
+
+// WithStackTrace sets whether the stack trace should be written as the "stack_trace" attribute.
+// It is enabled by default for the error methods such as Logger.Error.
+// The stack trace carried by the error (e.g. errors.WithStack of github.com/pkg/errors) is preferred,
+// and the stack of the caller is captured otherwise.
+func WithStackTrace(enabled bool) EntryOption {
+	return func(o *Entry) {
+		o.stackTrace = enabled
+	}
+}
+
+// WithStackDepth sets the maximum number of frames in the stack trace of the entry,
+// overriding the default of the logger set by WithDefaultStackDepth. 0 disables the stack trace.
+func WithStackDepth(depth int) EntryOption {
+	return func(o *Entry) {
+		o.stackDepth = depth
+		o.hasStackDepth = true
+	}
+}
+
 // WithErrorReport sets whether the entry should be reported as an error.
+// It takes effect at any severity, e.g. to track a warning in Error Reporting.
 func WithErrorReport(report bool) EntryOption {
 	return func(o *Entry) {
 		o.errorReport = report
 	}
 }
 
+// WithErrorReportMinLevel sets the minimum level of the entries of the error methods such as Logger.Error
+// to be reported as errors, which is LevelError by default. e.g. LevelCritical keeps the expected errors
+// logged by Logger.Error out of Error Reporting. The entries with WithErrorReport(true) are reported regardless.
+func WithErrorReportMinLevel(level slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.errorReportLevel = level
+	}
+}
+
 // Design note:
-// The write method is the only method to output the log entry.
+// The write method is the only method to output the log entry, except for WriteBatch.
 // And we keep it called by user's code with just one level of wrapping.
-func (l *Logger) write(ctx context.Context, entry Entry) {
-	if !l.handler.Enabled(ctx, entry.level) {
-		return
+// The only other entrance to handle is the slog.Handler, which is given the PC by log/slog.
+// write reports false when the entry is disabled by the level or dropped before it is built, and true otherwise.
+func (l *Logger) write(ctx context.Context, entry Entry) bool {
+	// 1: Logger.write, 2: Logger.<Exported Method>
+	pc, ok := l.prepare(ctx, &entry, 2)
+	if !ok {
+		return false
 	}
+	l.handle(ctx, entry, pc)
+	return true
+}
 
-	// generate information to ensure the uniqueness of the entry
-	now := time.Now()
-	insertId := uuid.NewString()
+// prepare reports whether the entry should be emitted, and captures its caller and stack trace if so.
+// depth is the number of frames between prepare and the user's code.
+func (l *Logger) prepare(ctx context.Context, entry *Entry, depth int) (uintptr, bool) {
+	if !l.Enabled(ctx, entry.level) {
+		l.drop(ctx, *entry, DropReasonLevelFiltered)
+		return 0, false
+	}
+	if l.skipCanceled && entry.level < LevelError && ctx.Err() != nil {
+		l.drop(ctx, *entry, DropReasonContextCanceled)
+		return 0, false
+	}
+	if !l.sampled(ctx, *entry) {
+		return 0, false
+	}
 
-	// 0: runtime.Callers, 1: Logger.write, 2: Logger.<Exported Method>, 3: <Your Code>
-	const defaultSkipCaller = 3
+	// 0: runtime.Callers, 1: Logger.prepare, 2...: the callers up to <Your Code>
+	skip := 2 + depth + l.skipCaller + entry.skipCaller
 	pcs := [1]uintptr{}
-	runtime.Callers(defaultSkipCaller+entry.skipCaller, pcs[:])
-	r := slog.NewRecord(now, entry.level, entry.msg, pcs[0])
+	if l.needsSource(*entry) {
+		runtime.Callers(skip, pcs[:])
+	}
+	if !entry.hasStackDepth {
+		entry.stackDepth = l.stackDepth
+	}
+	if entry.stackTrace && entry.stackDepth > 0 {
+		if entry.stack = errorStack(entry.err); entry.stack == nil {
+			// capture one more frame at least, to tell whether there are more frames than the depth
+			entry.stack = make([]uintptr, max(maxStackDepth, entry.stackDepth+1))
+			entry.stack = entry.stack[:runtime.Callers(skip, entry.stack)]
+		}
+	}
+	return pcs[0], true
+}
+
+// handle builds the record of the enabled entry and emits it.
+func (l *Logger) handle(ctx context.Context, entry Entry, pc uintptr) {
+	r := l.record(ctx, entry, pc)
+	if l.aggregator != nil && l.aggregator.add(r) {
+		return
+	}
+	if l.limiter != nil && l.limiter.suppress(r) {
+		l.drop(ctx, entry, DropReasonThrottled)
+		return
+	}
+	if l.onEntry != nil {
+		l.onEntry(ctx, entry)
+	}
+	l.emit(ctx, r)
+}
+
+// record builds the record of the enabled entry.
+func (l *Logger) record(ctx context.Context, entry Entry, pc uintptr) slog.Record {
+	if l.stripANSI {
+		entry.msg = stripANSI(entry.msg)
+		entry.additionalAttrs = stripANSIAttrs(entry.additionalAttrs)
+	}
 
-	attrs := []slog.Attr{
-		slog.String(logInsertIDKey, insertId),
+	// generate information to ensure the uniqueness of the entry
+	now := entry.time
+	if now.IsZero() {
+		now = l.now()
+	}
+	var insertId string
+	switch {
+	case entry.insertIDKey != "":
+		insertId = keyedInsertID(entry.insertIDKey)
+	case !l.contentInsertID:
+		insertId = l.newInsertID(ctx)
 	}
+
+	r := slog.NewRecord(now, entry.level, entry.msg, pc)
+
+	buf := attrPool.Get().(*[]slog.Attr)
+	attrs := append((*buf)[:0], slog.String(KeyInsertID, insertId))
 	if entry.errorReport {
 		attrs = append(attrs, logAttrReporting)
+		if l.service != "" {
+			attrs = append(attrs, l.serviceContextAttr())
+		}
+		if entry.stack == nil && pc != 0 {
+			attrs = append(attrs, slog.Any(KeyContext, reportLocationValue{pc}))
+		}
+	}
+	if labels, ok := l.labelsAttr(ctx, entry); ok {
+		attrs = append(attrs, labels)
 	}
 	if traceID := l.getTraceID(ctx); traceID != "" {
-		attrs = append(attrs, slog.String(logTraceKey, fmt.Sprintf("projects/%s/traces/%s", l.projectID, traceID)))
+		attrs = append(attrs, slog.String(KeyTrace, fmt.Sprintf("projects/%s/traces/%s", l.projectID, traceID)))
 		if spanID := l.getSpanID(ctx); spanID != "" {
-			attrs = append(attrs, slog.String(logSpanIDKey, spanID))
+			if spanID, ok := l.normalizeSpanID(spanID); ok {
+				attrs = append(attrs, slog.String(KeySpanID, spanID))
+			}
+		}
+		if l.getTraceSampled != nil && l.getTraceSampled(ctx) {
+			attrs = append(attrs, slog.Bool(KeyTraceSampled, true))
+		}
+	} else if l.getCorrelationID != nil {
+		if id := l.getCorrelationID(ctx); id != "" {
+			attrs = append(attrs, slog.String(attrCorrelationID, id))
+		}
+	}
+	if entry.httpRequest != nil {
+		attrs = append(attrs, slog.Any(KeyHTTPRequest, httpRequestValue{entry.httpRequest}))
+	}
+	if entry.operation == nil {
+		entry.operation = operationFromContext(ctx)
+	}
+	if entry.operation != nil {
+		attrs = append(attrs, slog.Any(KeyOperation, operationValue{entry.operation}))
+	}
+	if entry.stack != nil {
+		attrs = append(attrs, slog.Any(KeyStackTrace, stackValue{entry}))
+	}
+	if l.goroutineID {
+		attrs = append(attrs, slog.Uint64(attrGoroutine, goroutineID()))
+	}
+	attrs = append(attrs, entry.rawFields...)
+	user := len(attrs)
+	if l.name != "" {
+		attrs = append(attrs, slog.String(attrLogger, l.name))
+	}
+	attrs = appendResolved(attrs, l.attrs)
+	grouped := len(attrs)
+	attrs = appendResolved(attrs, attrsFromContext(ctx))
+	if entry.errorAttr != nil {
+		attrs = append(attrs, slog.String(KeyError, l.formatErr(entry, entry.errorAttr)))
+	}
+	attrs = appendResolved(attrs, entry.additionalAttrs)
+	if len(l.goas) > 0 {
+		// the groups keep the slice, so it must not be the buffer
+		inner := slices.Clone(attrs[grouped:])
+		clear(attrs[grouped:])
+		attrs = appendResolved(attrs[:grouped], buildAttrs(l.goas, inner))
+	}
+	if l.maxStringBytes > 0 {
+		for i := user; i < len(attrs); i++ {
+			attrs[i], _ = truncateAttr(attrs[i], l.maxStringBytes)
 		}
 	}
-	attrs = append(attrs, entry.additionalAttrs...)
-	r.AddAttrs(attrs...)
+	if entry.insertIDKey == "" && l.contentInsertID {
+		attrs[0] = slog.String(KeyInsertID, l.hashInsertID(r, attrs[1:]))
+	}
+	r.AddAttrs(attrs[:user]...)
+	if userAttrs := dedupeAttrs(attrs[user:]); l.payloadGroup != "" {
+		if len(userAttrs) > 0 {
+			// the group keeps the slice, so it must not be the buffer
+			r.AddAttrs(slog.Attr{Key: l.payloadGroup, Value: slog.GroupValue(slices.Clone(userAttrs)...)})
+		}
+	} else {
+		l.renameReserved(attrs[:user], userAttrs)
+		r.AddAttrs(userAttrs...) // the record copies the attrs, so the buffer can be reused
+	}
+	clear(attrs) // not to retain the values
+	*buf = attrs[:0]
+	attrPool.Put(buf)
+	return r
+}
 
-	// It is safe to retry because the uniqueness of the entry is guaranteed by time and insertId.
-	// TODO: consider to use some kind of retry strategy
-	l.handler.Handle(ctx, r)
+// attrPool holds the buffers to build the attrs of the records.
+var attrPool = sync.Pool{
+	New: func() any {
+		attrs := make([]slog.Attr, 0, 16)
+		return &attrs
+	},
+}
+
+// dedupeAttrs removes the attrs whose keys appear again later in place, so that the last one wins.
+// The order of the remaining attrs is kept. Attrs with the empty key (inlined groups) are always kept.
+func dedupeAttrs(attrs []slog.Attr) []slog.Attr {
+	n := 0
+	for i, a := range attrs {
+		if a.Key == "" || !hasKey(attrs[i+1:], a.Key) {
+			attrs[n] = a
+			n++
+		}
+	}
+	return attrs[:n]
+}
+
+// reservedKeyPrefix is prepended to the keys of the user attrs colliding with the top-level fields.
+const reservedKeyPrefix = "user_"
+
+// renameReserved prefixes the keys of the user attrs colliding with the top-level fields in place,
+// i.e. the built-in fields of log/slog, the special fields and the fields of the entry such as the raw fields,
+// so that the JSON has no duplicate keys. The built-in ones can't be told from the user ones by the handler.
+func (l *Logger) renameReserved(fields, userAttrs []slog.Attr) {
+	for i, a := range userAttrs {
+		switch {
+		case a.Key == slog.LevelKey, a.Key == slog.MessageKey, a.Key == slog.SourceKey,
+			a.Key == l.messageKey, reservedKeys[a.Key], hasKey(fields, a.Key):
+			userAttrs[i].Key = reservedKeyPrefix + a.Key
+		}
+	}
+}
+
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// appendResolved appends the attrs whose slog.LogValuer values are resolved, including those in groups,
+// so that the GCP key rewrites, the redactor and the asynchronous handler see the values at the time of logging.
+func appendResolved(dst, attrs []slog.Attr) []slog.Attr {
+	for _, a := range attrs {
+		dst = append(dst, resolveAttr(a))
+	}
+	return dst
+}
+
+func resolveAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		a.Value = slog.GroupValue(appendResolved(nil, a.Value.Group())...)
+	}
+	return a
+}
+
+// emit passes the record to the handler.
+func (l *Logger) emit(ctx context.Context, r slog.Record) {
+	if l.async != nil {
+		if !l.async.enqueue(l, ctx, []slog.Record{r}) {
+			l.drop(ctx, NewEntry(r.Level, r.Message), DropReasonBufferFull)
+		}
+		return
+	}
+	l.handleRecords(ctx, []slog.Record{r})
+}
+
+// emitBatch passes the records to the handler without interleaving the records of other goroutines.
+func (l *Logger) emitBatch(ctx context.Context, rs []slog.Record) {
+	if l.async != nil {
+		if !l.async.enqueue(l, ctx, rs) {
+			for _, r := range rs {
+				l.drop(ctx, NewEntry(r.Level, r.Message), DropReasonBufferFull)
+			}
+		}
+		return
+	}
+	l.handleRecords(ctx, rs)
+}
+
+// handleRecords passes the records to the handler, and retries those which failed without holding emitMu.
+// The errors are reported after unlocking, since the error handler may log through the logger.
+func (l *Logger) handleRecords(ctx context.Context, rs []slog.Record) {
+	for _, f := range l.handleLocked(ctx, rs) {
+		err := f.err
+		if f.retry {
+			err = l.retryUnlocked(ctx, f.r, err)
+		}
+		if err != nil {
+			l.handleError(ctx, err)
+		}
+	}
+}
+
+// failedRecord is the record which the handler failed to write.
+type failedRecord struct {
+	r     slog.Record
+	err   error
+	retry bool // whether the record is still to be retried
+}
+
+// handleLocked passes the records to the handler holding emitMu, which is released even if the handler panics.
+func (l *Logger) handleLocked(ctx context.Context, rs []slog.Record) []failedRecord {
+	l.emitMu.Lock()
+	defer l.emitMu.Unlock()
+	var failed []failedRecord
+	for _, r := range rs {
+		if f, ok := l.handleRecord(ctx, r); !ok {
+			failed = append(failed, f)
+		}
+	}
+	return failed
+}
+
+// handleRecord signs the record and makes the first attempt to pass it to the handler. The caller must hold emitMu.
+// With WithHashChain, the retries are made here as well, since no record can be written before the chained one.
+func (l *Logger) handleRecord(ctx context.Context, r slog.Record) (failedRecord, bool) {
+	chained := false
+	if l.checksum != nil {
+		var replace func([]string, slog.Attr) slog.Attr
+		if l.redactor != nil {
+			replace = l.replaceAttr
+		}
+		done := l.checksum.sign(&r, l.severity(r.Level), replace)
+		defer done()
+		chained = l.checksum.chain
+	}
+
+	err := l.handleOnce(ctx, r)
+	if err != nil && chained {
+		err = l.retryAfter(ctx, r, err, func() error { return l.handleOnce(ctx, r) })
+	}
+	if err != nil {
+		return failedRecord{r: r, err: err, retry: !chained}, false
+	}
+	l.handled(r)
+	return failedRecord{}, true
+}
+
+// handled counts the record written by the handler.
+func (l *Logger) handled(r slog.Record) {
+	if l.metrics != nil {
+		l.metrics(r.Level)
+	}
+}
+
+// WriteBatch writes the entries at once, so that the entries of other goroutines are not interleaved with them.
+// Each entry has its own timestamp and insertId, while the trace is taken from ctx for all of them.
+// The entries are not aggregated by WithAggregation, while each of them is counted by WithErrorRateLimit.
+func (l *Logger) WriteBatch(ctx context.Context, entries []Entry) {
+	records := make([]slog.Record, 0, len(entries))
+	for _, entry := range entries {
+		// 1: Logger.WriteBatch
+		pc, ok := l.prepare(ctx, &entry, 1)
+		if !ok {
+			continue
+		}
+		r := l.record(ctx, entry, pc)
+		if l.limiter != nil && l.limiter.suppress(r) {
+			l.drop(ctx, entry, DropReasonThrottled)
+			continue
+		}
+		records = append(records, r)
+		if l.onEntry != nil {
+			l.onEntry(ctx, entry)
+		}
+	}
+	if len(records) > 0 {
+		l.emitBatch(ctx, records)
+	}
 }
 
 func (l *Logger) Default(ctx context.Context, msg string, opts ...EntryOption) {
@@ -218,33 +1093,64 @@ func (l *Logger) Warn(ctx context.Context, msg string, opts ...EntryOption) {
 	l.write(ctx, NewEntry(LevelWarning, msg, opts...))
 }
 
+// errorEntry builds the entry of the error, which is reported as an error with the stack trace by default.
+func (l *Logger) errorEntry(level slog.Level, err error, opts []EntryOption) Entry {
+	entry := Entry{
+		level:      level,
+		err:        err,
+		stackTrace: true,
+	}
+	for _, apply := range opts {
+		apply(&entry)
+	}
+	entry.msg = l.formatErr(entry, err)
+	entry.errorReport = entry.errorReport || level >= l.errorReportLevel
+	if l.contextErrorPolicy != nil {
+		l.contextErrorPolicy.apply(&entry)
+	}
+	l.applyErrorLabels(&entry)
+	if attrs := errorAttrs(err); len(attrs) > 0 {
+		entry.additionalAttrs = append(attrs, entry.additionalAttrs...)
+	}
+	if l.errorDetails && err != nil {
+		entry.additionalAttrs = append([]slog.Attr{errorDetailsAttr(err)}, entry.additionalAttrs...)
+	}
+	return entry
+}
+
 func (l *Logger) Error(ctx context.Context, err error, opts ...EntryOption) {
-	entry := NewEntry(LevelError, l.printErr(err), opts...)
-	entry.errorReport = true
-	l.write(ctx, entry)
+	l.write(ctx, l.errorEntry(LevelError, err, opts))
 }
 
 func (l *Logger) Critical(ctx context.Context, err error, opts ...EntryOption) {
-	entry := NewEntry(LevelCritical, l.printErr(err), opts...)
-	entry.errorReport = true
-	l.write(ctx, entry)
+	l.write(ctx, l.errorEntry(LevelCritical, err, opts))
 }
 
 func (l *Logger) Alert(ctx context.Context, err error, opts ...EntryOption) {
-	entry := NewEntry(LevelAlert, l.printErr(err), opts...)
-	entry.errorReport = true
-	l.write(ctx, entry)
+	l.write(ctx, l.errorEntry(LevelAlert, err, opts))
 }
 
 func (l *Logger) Emergency(ctx context.Context, err error, opts ...EntryOption) {
-	entry := NewEntry(LevelEmergency, l.printErr(err), opts...)
-	entry.errorReport = true
-	l.write(ctx, entry)
+	l.write(ctx, l.errorEntry(LevelEmergency, err, opts))
+}
+
+// Fatal writes the entry at LevelCritical, closes the logger and calls os.Exit(1).
+// The logger is closed regardless of the cancellation of ctx, which is often the reason of the fatal error,
+// but giving up after fatalCloseTimeout not to hang the exit. The exit does not depend on whether the entry is emitted.
+func (l *Logger) Fatal(ctx context.Context, err error, opts ...EntryOption) {
+	l.write(ctx, l.errorEntry(LevelCritical, err, opts))
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fatalCloseTimeout)
+	defer cancel()
+	l.Close(ctx)
+	l.exit(1)
 }
 
+const fatalCloseTimeout = 10 * time.Second
+
 // Custom provides you a way to write a log entry with high flexibility,
 // but we will not make an effort to keep the backward compatibility of this method.
 // We recommend you to implement your own logger when you want to use this method.
-func (l *Logger) Custom(ctx context.Context, entry Entry) {
-	l.write(ctx, entry)
+// It reports false when the entry is disabled by the level or dropped before it is built.
+func (l *Logger) Custom(ctx context.Context, entry Entry) bool {
+	return l.write(ctx, entry)
 }
@@ -0,0 +1,821 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+// user logs as the group of the id and the role, hiding the other fields.
+type user struct {
+	id, role, password string
+}
+
+func (u user) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("id", u.id), slog.String("role", u.role))
+}
+
+// panicHandler panics on the first record and counts the rest.
+type panicHandler struct {
+	handled chan struct{}
+	fired   bool
+}
+
+func (h *panicHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *panicHandler) Handle(context.Context, slog.Record) error {
+	if !h.fired {
+		h.fired = true
+		panic("handler panicked")
+	}
+	h.handled <- struct{}{}
+	return nil
+}
+func (h *panicHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *panicHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestHandlerPanicReleasesLock(t *testing.T) {
+	h := &panicHandler{handled: make(chan struct{}, 1)}
+	l := logger.New(nil, "p", logger.LevelInfo, logger.WithHandler(h))
+	ctx := context.Background()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("the panic of the handler is not propagated")
+			}
+		}()
+		l.Info(ctx, "first")
+	}()
+
+	go l.Info(ctx, "second")
+	select {
+	case <-h.handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the logger is locked after the handler panicked")
+	}
+}
+
+func TestFatalWithCanceledContext(t *testing.T) {
+	var code int
+	l, capture := logtest.NewCapture(logger.WithAsync(16), logger.WithExitFunc(func(c int) { code = c }))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	l.Fatal(ctx, errors.New("fatal"))
+
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	entries := capture.Entries()
+	if len(entries) != 1 || entries[0].Message != "fatal" {
+		t.Fatalf("entries = %+v, want the fatal entry", entries)
+	}
+}
+
+func TestUserTimeAttr(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	l.Info(context.Background(), "hello", logger.WithAttrs(slog.String("time", "yesterday")))
+
+	if entries := capture.Entries(); len(entries) != 1 || entries[0].Message != "hello" {
+		t.Fatalf("entries = %+v, want the entry", entries)
+	}
+}
+
+func TestUserBuiltinKeyAttrs(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	l.Info(context.Background(), "hello", logger.WithAttrs(
+		slog.String("level", "high"),
+		slog.Int("source", 1),
+		slog.String("msg", "user message"),
+		slog.Any("time", slog.LevelError),
+	))
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "hello" || e.Level != logger.LevelInfo {
+		t.Errorf("message = %q, level = %v, want the built-in ones", e.Message, e.Level)
+	}
+	for key, want := range map[string]any{
+		"user_level":  "high",
+		"user_source": float64(1),
+		"user_msg":    "user message",
+		"user_time":   "ERROR",
+	} {
+		if got := e.Attrs[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestUserReservedKeyAttrs(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	l.Info(context.Background(), "hello",
+		logger.WithRawField("raw", "field"),
+		logger.WithAttrs(
+			slog.String(logger.KeySeverity, "user severity"),
+			slog.String(logger.KeyMessage, "user message"),
+			slog.String(logger.KeyTrace, "user trace"),
+			slog.String("raw", "user raw"),
+		))
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "hello" || e.Level != logger.LevelInfo || e.Trace != "" || e.Attrs["raw"] != "field" {
+		t.Errorf("entry = %+v, want the fields of the entry", e)
+	}
+	for key, want := range map[string]any{
+		"user_" + logger.KeySeverity: "user severity",
+		"user_" + logger.KeyMessage:  "user message",
+		"user_" + logger.KeyTrace:    "user trace",
+		"user_raw":                   "user raw",
+	} {
+		if got := e.Attrs[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestTraceSampled(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		traceID     string
+		sampled     bool
+		wantSampled any
+	}{
+		{name: "sampled", traceID: "trace", sampled: true, wantSampled: true},
+		{name: "not sampled", traceID: "trace", sampled: false, wantSampled: nil},
+		{name: "no trace", traceID: "", sampled: true, wantSampled: nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l, capture := logtest.NewCapture(
+				logger.WithTraceID(func(context.Context) string { return tt.traceID }),
+				logger.WithTraceSampled(func(context.Context) bool { return tt.sampled }),
+			)
+
+			l.Info(context.Background(), "hello")
+
+			entries := capture.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if got := entries[0].Attrs[logger.KeyTraceSampled]; got != tt.wantSampled {
+				t.Errorf("trace_sampled = %v, want %v", got, tt.wantSampled)
+			}
+		})
+	}
+}
+
+func TestTraceSampledWithoutFunc(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithTraceID(func(context.Context) string { return "trace" }))
+
+	l.Info(context.Background(), "hello")
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if entries[0].Trace != "projects/"+logtest.ProjectID+"/traces/trace" {
+		t.Errorf("trace = %q, want the one of the project", entries[0].Trace)
+	}
+	if got, ok := entries[0].Attrs[logger.KeyTraceSampled]; ok {
+		t.Errorf("trace_sampled = %v, want none", got)
+	}
+}
+
+func TestWithAttrsAccumulate(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	l.Info(context.Background(), "hello",
+		logger.WithAttrs(slog.String("a", "1")),
+		logger.WithAttrs(slog.String("b", "2"), slog.String("c", "3")),
+	)
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	for key, want := range map[string]string{"a": "1", "b": "2", "c": "3"} {
+		if got := entries[0].Attrs[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+// sourceLocation returns the source location of the entry, failing the test if it is malformed.
+func sourceLocation(t *testing.T, e logtest.Entry) (file, line, function string) {
+	t.Helper()
+	loc, ok := e.Attrs[logger.KeySourceLocation].(map[string]any)
+	if !ok {
+		t.Fatalf("sourceLocation = %v, want an object", e.Attrs[logger.KeySourceLocation])
+	}
+	file, _ = loc["file"].(string)
+	line, ok = loc["line"].(string)
+	if !ok {
+		t.Fatalf("sourceLocation.line = %#v, want a string", loc["line"])
+	}
+	function, _ = loc["function"].(string)
+	return file, line, function
+}
+
+func TestSourceLocation(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	_, wantFile, wantLine, _ := runtime.Caller(0)
+	l.Info(context.Background(), "hello")
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	file, line, function := sourceLocation(t, entries[0])
+	if file != wantFile || line != strconv.Itoa(wantLine+1) || function != "github.com/ebi-yade/osuite/logger_test.TestSourceLocation" {
+		t.Errorf("sourceLocation = %s:%s %s, want %s:%d of the test", file, line, function, wantFile, wantLine+1)
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	l, capture := logtest.NewCapture()
+	ctx := context.Background()
+	l.SetLevel(logger.LevelInfo)
+
+	l.Debug(ctx, "suppressed")
+	l.SetLevel(logger.LevelDebug)
+	l.Debug(ctx, "emitted")
+
+	if got := l.Level(); got != logger.LevelDebug {
+		t.Errorf("Level() = %v, want %v", got, logger.LevelDebug)
+	}
+	entries := capture.Entries()
+	if len(entries) != 1 || entries[0].Message != "emitted" {
+		t.Errorf("entries = %+v, want only the one after SetLevel", entries)
+	}
+}
+
+func TestLogValuer(t *testing.T) {
+	l, capture := logtest.NewCapture()
+	u := user{id: "u1", role: "admin", password: "secret"}
+
+	l.With(slog.Any("owner", u)).Info(context.Background(), "hello", logger.WithAttrs(slog.Any("user", u)))
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	for _, key := range []string{"user", "owner"} {
+		got, _ := entries[0].Attrs[key].(map[string]any)
+		if len(got) != 2 || got["id"] != "u1" || got["role"] != "admin" {
+			t.Errorf("%s = %v, want the resolved value", key, entries[0].Attrs[key])
+		}
+	}
+}
+
+func TestNestedBuiltinKeys(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	l.Info(context.Background(), "hello", logger.WithAttrs(slog.Group("payload",
+		slog.String("message", "nested message"),
+		slog.String("msg", "nested msg"),
+		slog.String("severity", "nested severity"),
+		slog.String("level", "nested level"),
+		slog.String("source", "nested source"),
+	)))
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if entries[0].Message != "hello" || entries[0].Level != logger.LevelInfo {
+		t.Errorf("entry = %+v, want the top-level message and severity", entries[0])
+	}
+	payload, _ := entries[0].Attrs["payload"].(map[string]any)
+	for _, key := range []string{"message", "msg", "severity", "level", "source"} {
+		if got, want := payload[key], "nested "+key; got != want {
+			t.Errorf("payload.%s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+// logHelper wraps the logger as the helpers of the teams do.
+func logHelper(l *logger.Logger, msg string, opts ...logger.EntryOption) {
+	l.Info(context.Background(), msg, opts...)
+}
+
+// logHelper2 wraps logHelper, one more frame to skip.
+func logHelper2(l *logger.Logger, msg string) {
+	logHelper(l, msg, logger.WithSkipCaller(1))
+}
+
+func TestDefaultSkipCaller(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithDefaultSkipCaller(1))
+
+	_, wantFile, wantLine, _ := runtime.Caller(0)
+	logHelper(l, "helper")
+	logHelper2(l, "helper2")
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, e := range entries {
+		file, line, function := sourceLocation(t, e)
+		if want := strconv.Itoa(wantLine + 1 + i); file != wantFile || line != want || function != "github.com/ebi-yade/osuite/logger_test.TestDefaultSkipCaller" {
+			t.Errorf("%s: sourceLocation = %s:%s %s, want %s:%s of the test", e.Message, file, line, function, wantFile, want)
+		}
+	}
+}
+
+// BenchmarkSource shows the cost of the source location, which WithSource(false) saves:
+// runtime.Callers, the symbolization and the sourceLocation group.
+func BenchmarkSource(b *testing.B) {
+	for _, enabled := range []bool{true, false} {
+		b.Run(fmt.Sprintf("enabled=%t", enabled), func(b *testing.B) {
+			l := logger.New(io.Discard, "p", logger.LevelInfo, logger.WithSource(enabled))
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Info(ctx, "hello")
+			}
+		})
+	}
+}
+
+func TestWriteBatch(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithTraceID(func(context.Context) string { return "trace" }))
+	ctx := context.Background()
+	const batches, size = 20, 5
+
+	var wg sync.WaitGroup
+	for b := 0; b < batches; b++ {
+		wg.Add(2)
+		go func(b int) {
+			defer wg.Done()
+			entries := make([]logger.Entry, size)
+			for i := range entries {
+				entries[i] = logger.NewEntry(logger.LevelInfo, fmt.Sprintf("batch %d", b), logger.WithAttrs(slog.Int("i", i)))
+			}
+			l.WriteBatch(ctx, entries)
+		}(b)
+		go func() {
+			defer wg.Done()
+			l.Info(ctx, "single")
+		}()
+	}
+	wg.Wait()
+
+	entries := capture.Entries()
+	if len(entries) != batches*(size+1) {
+		t.Fatalf("got %d entries, want %d", len(entries), batches*(size+1))
+	}
+	insertIDs := make(map[string]bool)
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		insertIDs[e.InsertID] = true
+		if e.Trace != "projects/"+logtest.ProjectID+"/traces/trace" {
+			t.Errorf("entries[%d].Trace = %q, want the one of ctx", i, e.Trace)
+		}
+		if e.Message == "single" {
+			continue
+		}
+		// the entries of a batch are written in order without the others between them
+		for j := 0; j < size; j++ {
+			if i+j >= len(entries) || entries[i+j].Message != e.Message || entries[i+j].Attrs["i"] != float64(j) {
+				t.Fatalf("entries[%d] is not the entry %d of %q, the batch is interleaved", i+j, j, e.Message)
+			}
+			insertIDs[entries[i+j].InsertID] = true
+		}
+		i += size - 1
+	}
+	if len(insertIDs) != len(entries) {
+		t.Errorf("got %d insertIds for %d entries, want unique ones", len(insertIDs), len(entries))
+	}
+}
+
+// TestConcurrentWrites is meaningful with -race, since bytes.Buffer is not safe for concurrent use.
+func TestConcurrentWrites(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.New(&buf, "p", logger.LevelInfo)
+	ctx := context.Background()
+	const goroutines, writes = 300, 10
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				l.Info(ctx, "hello", logger.WithAttrs(slog.Int("goroutine", g), slog.Int("i", i)))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	if len(lines) != goroutines*writes {
+		t.Fatalf("got %d lines, want %d", len(lines), goroutines*writes)
+	}
+	for i, line := range lines {
+		if !json.Valid(line) {
+			t.Fatalf("lines[%d] = %s, want a valid JSON", i, line)
+		}
+	}
+}
+
+// BenchmarkAttrs reports the allocations of the entries with the attrs from the logger, the context and the entry,
+// which the pool of the attr buffers keeps low.
+func BenchmarkAttrs(b *testing.B) {
+	l := logger.New(io.Discard, "p", logger.LevelInfo,
+		logger.WithSource(false),
+		logger.WithTraceID(func(context.Context) string { return "trace" }),
+	).With(slog.String("service", "api"))
+	ctx := logger.ContextWithAttrs(context.Background(), slog.String("tenant_id", "t1"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info(ctx, "hello", logger.WithAttrs(slog.Int("i", i), slog.String("user_id", "u1")))
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	for _, tt := range []struct {
+		now  time.Time
+		want string
+	}{
+		{now: time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("JST", 9*60*60)), want: "2024-01-01T18:04:05.000000006Z"},
+		{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), want: "2024-01-02T03:04:05.000000000Z"},
+	} {
+		l, capture := logtest.NewCapture(logger.WithClock(func() time.Time { return tt.now }))
+
+		l.Info(context.Background(), "hello")
+
+		entries := capture.Entries()
+		if len(entries) != 1 {
+			t.Fatalf("got %d entries, want 1", len(entries))
+		}
+		if got := entries[0].Attrs[logger.KeyTime]; got != tt.want {
+			t.Errorf("time of %v = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestMessageKey(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithMessageKey("msg"))
+
+	l.Error(context.Background(), errors.New("failed"))
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Attrs["msg"] != "failed" {
+		t.Errorf("msg = %v, want the message", e.Attrs["msg"])
+	}
+	if e.Message != "" {
+		t.Errorf("message = %q, want none", e.Message)
+	}
+	if e.Level != logger.LevelError {
+		t.Errorf("level = %v, want %v", e.Level, logger.LevelError)
+	}
+	sourceLocation(t, e)
+}
+
+func TestSeverityMapper(t *testing.T) {
+	levelAudit := logger.LevelInfo + 2
+	l, capture := logtest.NewCapture(logger.WithSeverityMapper(func(level slog.Level) logging.Severity {
+		if level == levelAudit {
+			return logging.Notice
+		}
+		return logging.Severity(level)
+	}))
+	ctx := context.Background()
+
+	l.Custom(ctx, logger.NewEntry(levelAudit, "audit"))
+	l.Info(ctx, "info")
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []slog.Level{logger.LevelNotice, logger.LevelInfo} {
+		if got := entries[i].Level; got != want {
+			t.Errorf("severity of %q = %v, want %v", entries[i].Message, logging.Severity(got), logging.Severity(want))
+		}
+	}
+}
+
+func TestDedupeAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.New(&buf, "p", logger.LevelInfo).With(slog.String("a", "logger"), slog.String("b", "logger"))
+	ctx := logger.ContextWithAttrs(context.Background(), slog.String("b", "context"), slog.String("c", "context"))
+
+	l.Info(ctx, "hello", logger.WithAttrs(slog.String("c", "entry"), slog.String("d", "entry")))
+
+	line := buf.String()
+	for key, want := range map[string]string{"a": "logger", "b": "context", "c": "entry", "d": "entry"} {
+		if n := strings.Count(line, `"`+key+`":`); n != 1 {
+			t.Errorf("%s appears %d times in %s, want once", key, n, line)
+		}
+		if !strings.Contains(line, `"`+key+`":"`+want+`"`) {
+			t.Errorf("%s is not %q in %s", key, want, line)
+		}
+	}
+	if a, d := strings.Index(line, `"a":`), strings.Index(line, `"d":`); a > d {
+		t.Errorf("the order of the attrs is not kept in %s", line)
+	}
+}
+
+func TestSourceForErrorReports(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithSource(false))
+	ctx := context.Background()
+
+	l.Info(ctx, "info")
+	l.Warn(ctx, "reported warning", logger.WithErrorReport(true))
+	l.Error(ctx, errors.New("error"))
+
+	entries := capture.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if _, ok := entries[0].Attrs[logger.KeySourceLocation]; ok {
+		t.Errorf("info has the source location %v, want none", entries[0].Attrs[logger.KeySourceLocation])
+	}
+	for _, e := range entries[1:] {
+		if _, line, function := sourceLocation(t, e); line == "" || function != "github.com/ebi-yade/osuite/logger_test.TestSourceForErrorReports" {
+			t.Errorf("%s: sourceLocation = %s %s, want the one of the test", e.Message, line, function)
+		}
+	}
+}
+
+func TestErrorFormatter(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithPrintError(func(err error) string { return "logger: " + err.Error() }))
+	ctx := context.Background()
+	err := errors.New("failed")
+
+	l.Error(ctx, err, logger.WithErrorFormatter(func(err error) string { return "entry: " + err.Error() }))
+	l.Error(ctx, err)
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []string{"entry: failed", "logger: failed"} {
+		if entries[i].Message != want {
+			t.Errorf("entries[%d].Message = %q, want %q", i, entries[i].Message, want)
+		}
+	}
+}
+
+func TestErrorReportBelowError(t *testing.T) {
+	l, capture := logtest.NewCapture()
+	ctx := context.Background()
+
+	l.Warn(ctx, "degraded", logger.WithErrorReport(true))
+	l.Info(ctx, "reported info", logger.WithErrorReport(true))
+	l.Warn(ctx, "not reported")
+
+	entries := capture.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, want := range []bool{true, true, false} {
+		if e := entries[i]; e.ErrorReport != want {
+			t.Errorf("%s: error report = %t, want %t", e.Message, e.ErrorReport, want)
+		}
+	}
+	if e := entries[0]; e.Level != logger.LevelWarning || e.Attrs[logger.KeyContext] == nil {
+		t.Errorf("entry = %+v, want the warning with the report location", e)
+	}
+}
+
+// BenchmarkDropped shows that the dropped entries cost no runtime.Callers, symbolization nor allocation,
+// compared to BenchmarkSource.
+func BenchmarkDropped(b *testing.B) {
+	for name, l := range map[string]*logger.Logger{
+		"disabled": logger.New(io.Discard, "p", logger.LevelInfo),
+		"sampled":  logger.New(io.Discard, "p", logger.LevelDebug, logger.WithSampler(func(slog.Level) bool { return false })),
+	} {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Debug(ctx, "dropped")
+			}
+		})
+	}
+}
+
+// decorate adds the common attrs to the entry, as the reusable decorators do.
+func decorate(entry logger.Entry) logger.Entry {
+	entry.AddAttrs(slog.String("region", "asia-northeast1"))
+	return entry
+}
+
+func TestEntryDecorator(t *testing.T) {
+	l, capture := logtest.NewCapture()
+	original := logger.NewEntry(logger.LevelWarning, "hello", logger.WithAttrs(slog.Int("n", 1)), logger.WithErrorReport(true))
+
+	decorated := decorate(original)
+	l.Custom(context.Background(), decorated)
+
+	if original.Level() != logger.LevelWarning || original.Message() != "hello" || !original.IsErrorReport() || original.Err() != nil {
+		t.Errorf("accessors = %v %q %t %v, want those of NewEntry", original.Level(), original.Message(), original.IsErrorReport(), original.Err())
+	}
+	if got := original.Attrs(); len(got) != 1 {
+		t.Errorf("attrs of the original = %v, want those of NewEntry only", got)
+	}
+	if got := decorated.Attrs(); len(got) != 2 || got[1].Key != "region" {
+		t.Errorf("attrs of the decorated = %v, want the added one at the end", got)
+	}
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if e := entries[0]; e.Message != "hello" || !e.ErrorReport || e.Attrs["n"] != float64(1) || e.Attrs["region"] != "asia-northeast1" {
+		t.Errorf("entry = %+v, want the decorated one", e)
+	}
+}
+
+func TestPayloadGroup(t *testing.T) {
+	l, capture := logtest.NewCapture(
+		logger.WithPayloadGroup("payload"),
+		logger.WithTraceID(func(context.Context) string { return "trace" }),
+	)
+
+	l.Named("api").Warn(context.Background(), "hello",
+		logger.WithAttrs(slog.String("user_id", "u1")),
+		logger.WithRawField("raw", "field"),
+		logger.WithLabels(map[string]string{"env": "test"}),
+	)
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "hello" || e.Level != logger.LevelWarning || e.Trace == "" || e.InsertID == "" {
+		t.Errorf("entry = %+v, want the fields of Cloud Logging at the top level", e)
+	}
+	for _, key := range []string{logger.KeyTime, logger.KeySourceLocation, logger.KeyLabels, "raw"} {
+		if _, ok := e.Attrs[key]; !ok {
+			t.Errorf("%s is not at the top level of %v", key, e.Attrs)
+		}
+	}
+	payload, _ := e.Attrs["payload"].(map[string]any)
+	if len(payload) != 2 || payload["user_id"] != "u1" || payload["logger"] != "api" {
+		t.Errorf("payload = %v, want the attrs of the entry", e.Attrs["payload"])
+	}
+	if _, ok := e.Attrs["user_id"]; ok {
+		t.Errorf("user_id is at the top level of %v", e.Attrs)
+	}
+}
+
+func TestRawField(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithPayloadGroup("payload"))
+
+	l.Info(context.Background(), "hello",
+		logger.WithRawField("appengine.googleapis.com/request_log_id", "log1"),
+		logger.WithRawField("custom", map[string]any{"n": 1}),
+	)
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if got := e.Attrs["appengine.googleapis.com/request_log_id"]; got != "log1" {
+		t.Errorf("request_log_id = %v, want log1", got)
+	}
+	if got, _ := e.Attrs["custom"].(map[string]any); got["n"] != float64(1) {
+		t.Errorf("custom = %v, want the value as it is", e.Attrs["custom"])
+	}
+	if _, ok := e.Attrs["payload"]; ok {
+		t.Errorf("payload = %v, want none without the user attrs", e.Attrs["payload"])
+	}
+}
+
+type debugKey struct{}
+
+// debugLevel enables the debug entries only for the contexts with the debug flag.
+func debugLevel(ctx context.Context) slog.Level {
+	if ctx.Value(debugKey{}) != nil {
+		return logger.LevelDebug
+	}
+	return logger.LevelInfo
+}
+
+func TestMinLevelFunc(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithMinLevelFunc(debugLevel))
+	debugCtx := context.WithValue(context.Background(), debugKey{}, true)
+
+	l.Debug(debugCtx, "emitted")
+	l.Debug(context.Background(), "suppressed")
+	l.Info(context.Background(), "info")
+
+	var got []string
+	for _, e := range capture.Entries() {
+		got = append(got, e.Message)
+	}
+	if fmt.Sprint(got) != "[emitted info]" {
+		t.Errorf("messages = %q, want the debug one of the debug context and the info one", got)
+	}
+}
+
+func TestFallbackCorrelationID(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		traceID string
+		want    any
+	}{
+		{name: "trace present", traceID: "trace", want: nil},
+		{name: "trace absent", traceID: "", want: "req-1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l, capture := logtest.NewCapture(
+				logger.WithTraceID(func(context.Context) string { return tt.traceID }),
+				logger.WithFallbackCorrelationID(func(context.Context) string { return "req-1" }),
+			)
+
+			l.Info(context.Background(), "hello")
+
+			entries := capture.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if got := entries[0].Attrs["correlation_id"]; got != tt.want {
+				t.Errorf("correlation_id = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorReportMinLevel(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithErrorReportMinLevel(logger.LevelCritical))
+	ctx := context.Background()
+
+	l.Error(ctx, errors.New("expected"))
+	l.Critical(ctx, errors.New("unexpected"))
+	l.Error(ctx, errors.New("forced"), logger.WithErrorReport(true))
+
+	entries := capture.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, want := range []bool{false, true, true} {
+		if e := entries[i]; e.ErrorReport != want {
+			t.Errorf("%s: error report = %t, want %t", e.Message, e.ErrorReport, want)
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	static := logger.New(io.Discard, "p", logger.LevelInfo)
+	dynamic := logger.New(io.Discard, "p", logger.LevelInfo, logger.WithMinLevelFunc(debugLevel))
+	debugCtx := context.WithValue(context.Background(), debugKey{}, true)
+
+	for _, tt := range []struct {
+		name  string
+		l     *logger.Logger
+		ctx   context.Context
+		level slog.Level
+		want  bool
+	}{
+		{name: "static debug", l: static, ctx: context.Background(), level: logger.LevelDebug, want: false},
+		{name: "static info", l: static, ctx: context.Background(), level: logger.LevelInfo, want: true},
+		{name: "dynamic debug", l: dynamic, ctx: context.Background(), level: logger.LevelDebug, want: false},
+		{name: "dynamic debug with flag", l: dynamic, ctx: debugCtx, level: logger.LevelDebug, want: true},
+	} {
+		if got := tt.l.Enabled(tt.ctx, tt.level); got != tt.want {
+			t.Errorf("%s: Enabled() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	static.SetLevel(logger.LevelDebug)
+	if !static.Enabled(context.Background(), logger.LevelDebug) {
+		t.Error("Enabled() = false after SetLevel, want true")
+	}
+}
@@ -0,0 +1,118 @@
+// Package logtest provides the helpers to assert the entries written by the logger.
+package logtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"cloud.google.com/go/logging"
+	"github.com/ebi-yade/osuite/logger"
+)
+
+// ProjectID is the projectID of the logger returned by NewCapture.
+const ProjectID = "logtest"
+
+const reportedErrorEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+
+// Entry is the decoded form of an emitted entry.
+type Entry struct {
+	Level       slog.Level
+	Message     string
+	Trace       string
+	SpanID      string
+	InsertID    string
+	ErrorReport bool
+	// Attrs holds the other fields of the entry as decoded by encoding/json,
+	// e.g. a group is a map[string]any and a number is a float64.
+	Attrs map[string]any
+}
+
+// Capture records the entries written by the logger returned by NewCapture.
+// It is safe for concurrent use.
+type Capture struct {
+	mu      sync.Mutex
+	buf     []byte
+	entries []Entry
+	err     error
+}
+
+// NewCapture returns the logger enabled for all levels, whose entries are recorded by the returned Capture.
+// The logger is built by logger.New, so the entries go through the same path as in production;
+// options producing non-JSON output such as logger.WithConsoleFormat must not be given.
+func NewCapture(opts ...logger.LoggerOption) (*logger.Logger, *Capture) {
+	c := &Capture{}
+	return logger.New(c, ProjectID, logger.LevelDefault, opts...), c
+}
+
+// Write decodes the written JSON lines.
+func (c *Capture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buf = append(c.buf, p...)
+	for {
+		i := bytes.IndexByte(c.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := c.buf[:i]
+		c.buf = c.buf[i+1:]
+		entry, err := decode(line)
+		if err != nil {
+			c.err = err
+			return len(p), err
+		}
+		c.entries = append(c.entries, entry)
+	}
+	return len(p), nil
+}
+
+// Entries returns a copy of the recorded entries in the written order.
+func (c *Capture) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Entry(nil), c.entries...)
+}
+
+// Err returns the first error of decoding, if any.
+func (c *Capture) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Reset discards the recorded entries.
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+func decode(line []byte) (Entry, error) {
+	var attrs map[string]any
+	if err := json.Unmarshal(line, &attrs); err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{Attrs: attrs}
+	if s, ok := attrs[logger.KeySeverity].(string); ok {
+		entry.Level = slog.Level(logging.ParseSeverity(s))
+	}
+	entry.Message, _ = attrs[logger.KeyMessage].(string)
+	entry.Trace, _ = attrs[logger.KeyTrace].(string)
+	entry.SpanID, _ = attrs[logger.KeySpanID].(string)
+	entry.InsertID, _ = attrs[logger.KeyInsertID].(string)
+	entry.ErrorReport = attrs[logger.KeyType] == reportedErrorEventType
+	for _, key := range []string{
+		logger.KeySeverity,
+		logger.KeyMessage,
+		logger.KeyTrace,
+		logger.KeySpanID,
+		logger.KeyInsertID,
+		logger.KeyType,
+	} {
+		delete(attrs, key)
+	}
+	return entry, nil
+}
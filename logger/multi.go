@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// NewMulti returns the logger writing the same entry to every writer.
+//
+// Unlike io.MultiWriter, a failure of one writer does not prevent the others from receiving the entry.
+// Such an entry is passed to the function set by WithErrorHandler without the retry of WithRetry,
+// which would write it again to the other writers; it is retried only when all the writers fail.
+// The write is synchronous and unbuffered: each entry is written to the writers one by one in order,
+// so a slow writer blocks the caller and delays the following writers.
+// Writers having a Flush method are flushed on Close.
+func NewMulti(writers []io.Writer, projectID string, minLevel slog.Level, opts ...LoggerOption) *Logger {
+	return New(multiWriter(writers), projectID, minLevel, opts...)
+}
+
+type multiWriter []io.Writer
+
+func (mw multiWriter) Write(p []byte) (int, error) {
+	var errs []error
+	for _, w := range mw {
+		if _, err := w.Write(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 && len(errs) < len(mw) {
+		return len(p), &partialWriteError{err: errors.Join(errs...)}
+	}
+	return len(p), errors.Join(errs...)
+}
+
+// partialWriteError is the error of multiWriter which some of the writers have written the entry despite,
+// so the entry is not retried not to duplicate it on them.
+type partialWriteError struct {
+	err error
+}
+
+func (e *partialWriteError) Error() string { return "partially written: " + e.err.Error() }
+func (e *partialWriteError) Unwrap() error { return e.err }
+
+func (mw multiWriter) Flush() error {
+	var errs []error
+	for _, w := range mw {
+		if f, ok := w.(interface{ Flush() error }); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+// countingWriter fails every write, counting them.
+type countingWriter struct{ writes int }
+
+func (w *countingWriter) Write([]byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestMultiPartialFailure(t *testing.T) {
+	healthy := &logtest.Capture{}
+	failing := &countingWriter{}
+	var errs []error
+	exhausted := 0
+	l := logger.NewMulti([]io.Writer{failing, healthy}, "p", logger.LevelInfo,
+		logger.WithRetryPolicy(logger.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			OnExhausted:    func(context.Context, slog.Record, error) { exhausted++ },
+		}),
+		logger.WithErrorHandler(func(_ context.Context, err error) { errs = append(errs, err) }),
+	)
+	l.Info(context.Background(), "hello")
+
+	if got := len(healthy.Entries()); got != 1 {
+		t.Errorf("the healthy writer got %d entries, want 1 without the retry", got)
+	}
+	if failing.writes != 1 {
+		t.Errorf("the failing writer got %d writes, want 1", failing.writes)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "disk full") || exhausted != 0 {
+		t.Errorf("errors = %v, exhausted %d times, want the partial failure reported once", errs, exhausted)
+	}
+}
+
+func TestMultiTotalFailure(t *testing.T) {
+	first, second := &countingWriter{}, &countingWriter{}
+	var errs []error
+	l := logger.NewMulti([]io.Writer{first, second}, "p", logger.LevelInfo,
+		logger.WithRetry(3, time.Millisecond),
+		logger.WithErrorHandler(func(_ context.Context, err error) { errs = append(errs, err) }),
+	)
+	l.Info(context.Background(), "hello")
+
+	if first.writes != 3 || second.writes != 3 {
+		t.Errorf("got %d and %d writes, want 3 attempts for each", first.writes, second.writes)
+	}
+	if len(errs) != 1 {
+		t.Errorf("errors = %v, want the last one", errs)
+	}
+}
@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewNop returns the logger which writes nothing, e.g. for tests and disabled paths.
+// Since every level is disabled, logging costs little more than the call itself.
+// Note that Fatal still exits.
+func NewNop() *Logger {
+	logger := newLogger("", LevelEmergency)
+	logger.handler = nopHandler{}
+	return logger
+}
+
+type nopHandler struct{}
+
+func (nopHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (nopHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h nopHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h nopHandler) WithGroup(string) slog.Handler           { return h }
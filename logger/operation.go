@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+)
+
+// WithOperation sets the operation field of the entry, which groups the entries of a long-running operation.
+// Mark the first and the last entries of the operation with first and last.
+// It takes precedence over the operation carried by the context of Logger.StartOperation.
+func WithOperation(id, producer string, first, last bool) EntryOption {
+	return func(o *Entry) {
+		o.operation = &loggingpb.LogEntryOperation{
+			Id:       id,
+			Producer: producer,
+			First:    first,
+			Last:     last,
+		}
+	}
+}
+
+// WithOperationProducer sets the producer of the operations started by Logger.StartOperation,
+// e.g. "github.com/MyProject/MyApplication". By default, it is the service set by WithServiceContext.
+func WithOperationProducer(producer string) LoggerOption {
+	return func(l *Logger) {
+		l.operationProducer = producer
+	}
+}
+
+type contextOperationKey struct{}
+
+// StartOperation writes the first entry of the operation at LevelInfo, and returns a copy of ctx carrying the operation,
+// so that the entries logged with it join the operation, e.g. the entries of a batch job while it runs.
+// Call Logger.EndOperation with the returned context to write the last entry.
+func (l *Logger) StartOperation(ctx context.Context, id, msg string, opts ...EntryOption) context.Context {
+	producer := l.operationProducer
+	if producer == "" {
+		producer = l.service
+	}
+	ctx = context.WithValue(ctx, contextOperationKey{}, &loggingpb.LogEntryOperation{Id: id, Producer: producer})
+	entry := NewEntry(LevelInfo, msg, opts...)
+	WithOperation(id, producer, true, false)(&entry)
+	l.write(ctx, entry)
+	return ctx
+}
+
+// EndOperation writes the last entry of the operation carried by ctx at LevelInfo.
+// The entry has no operation field if ctx does not carry one.
+func (l *Logger) EndOperation(ctx context.Context, msg string, opts ...EntryOption) {
+	entry := NewEntry(LevelInfo, msg, opts...)
+	if op := operationFromContext(ctx); op != nil {
+		WithOperation(op.Id, op.Producer, false, true)(&entry)
+	}
+	l.write(ctx, entry)
+}
+
+func operationFromContext(ctx context.Context) *loggingpb.LogEntryOperation {
+	op, _ := ctx.Value(contextOperationKey{}).(*loggingpb.LogEntryOperation)
+	return op
+}
+
+// operationValue renders the operation in the JSON representation of the LogEntryOperation message,
+// where first and last are omitted when false.
+type operationValue struct {
+	*loggingpb.LogEntryOperation
+}
+
+func (v operationValue) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("id", v.Id),
+		slog.String("producer", v.Producer),
+	}
+	if v.First {
+		attrs = append(attrs, slog.Bool("first", true))
+	}
+	if v.Last {
+		attrs = append(attrs, slog.Bool("last", true))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithOTelTrace sets the functions to get the traceID, the spanID and the sampled flag
+// from the span context of OpenTelemetry in context.
+// Nothing is emitted when the span context is absent or invalid.
+func WithOTelTrace() LoggerOption {
+	return func(l *Logger) {
+		l.getTraceID = func(ctx context.Context) string {
+			if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+				return sc.TraceID().String()
+			}
+			return ""
+		}
+		l.getSpanID = func(ctx context.Context) string {
+			if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+				return sc.SpanID().String()
+			}
+			return ""
+		}
+		l.getTraceSampled = func(ctx context.Context) bool {
+			return trace.SpanContextFromContext(ctx).IsSampled()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package otellog integrates the logger with OpenTelemetry.
+// The entries are correlated with the span in context, and optionally mirrored to the span as the events.
+package otellog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/ebi-yade/osuite/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type config struct {
+	events   bool
+	minLevel slog.Level
+}
+
+// Option configures the integration given by WithOTel.
+type Option func(*config)
+
+// WithSpanEvents mirrors the entries at the level or above to the recording span in context as the events.
+// The errors of the error methods such as Logger.Error are recorded by trace.Span.RecordError,
+// and the entries reported as errors set the status of the span to codes.Error.
+// The events are added by logger.WithEntryCallback, so the other callbacks of the logger are kept.
+func WithSpanEvents(minLevel slog.Level) Option {
+	return func(c *config) {
+		c.events = true
+		c.minLevel = minLevel
+	}
+}
+
+// WithOTel returns the option of the logger which emits the trace, the span and the sampled flag
+// of the span in context, as logger.WithOTelTrace does, with the options of the integration.
+func WithOTel(opts ...Option) logger.LoggerOption {
+	var c config
+	for _, apply := range opts {
+		apply(&c)
+	}
+	return func(l *logger.Logger) {
+		logger.WithOTelTrace()(l)
+		if c.events {
+			logger.WithEntryCallback(c.addEvent)(l)
+		}
+	}
+}
+
+func (c *config) addEvent(ctx context.Context, entry logger.Entry) {
+	if entry.Level() < c.minLevel {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	attrs := trace.WithAttributes(
+		attribute.String("log.severity", severityText(entry.Level())),
+		attribute.String("log.message", entry.Message()),
+	)
+	if err := entry.Err(); err != nil {
+		span.RecordError(err, attrs)
+	} else {
+		span.AddEvent("log", attrs)
+	}
+	if entry.IsErrorReport() {
+		span.SetStatus(codes.Error, entry.Message())
+	}
+}
+
+func severityText(level slog.Level) string {
+	switch {
+	case level >= logger.LevelEmergency:
+		return "EMERGENCY"
+	case level >= logger.LevelAlert:
+		return "ALERT"
+	case level >= logger.LevelCritical:
+		return "CRITICAL"
+	case level >= logger.LevelError:
+		return "ERROR"
+	case level >= logger.LevelWarning:
+		return "WARNING"
+	case level >= logger.LevelNotice:
+		return "NOTICE"
+	case level >= logger.LevelInfo:
+		return "INFO"
+	case level >= logger.LevelDebug:
+		return "DEBUG"
+	default:
+		return "DEFAULT"
+	}
+}
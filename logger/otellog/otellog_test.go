@@ -0,0 +1,104 @@
+package otellog_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+	"github.com/ebi-yade/osuite/logger/otellog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// startSpan starts the span recorded by the returned recorder.
+func startSpan(t *testing.T) (context.Context, *tracetest.SpanRecorder) {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+	ctx, _ := tp.Tracer("otellog_test").Start(context.Background(), "op")
+	return ctx, rec
+}
+
+func TestWithOTel(t *testing.T) {
+	ctx, rec := startSpan(t)
+	l, c := logtest.NewCapture(otellog.WithOTel())
+	l.Info(ctx, "hello")
+
+	sc := rec.Started()[0].SpanContext()
+	e := c.Entries()[0]
+	if want := "projects/" + logtest.ProjectID + "/traces/" + sc.TraceID().String(); e.Trace != want {
+		t.Errorf("trace = %q, want %q", e.Trace, want)
+	}
+	if e.SpanID != sc.SpanID().String() {
+		t.Errorf("spanId = %q, want %q", e.SpanID, sc.SpanID())
+	}
+	if e.Attrs[logger.KeyTraceSampled] != true {
+		t.Errorf("trace_sampled = %v, want true for the sampled span", e.Attrs[logger.KeyTraceSampled])
+	}
+
+	rec.Started()[0].End()
+	if events := rec.Ended()[0].Events(); len(events) != 0 {
+		t.Errorf("got %d events, want none without WithSpanEvents", len(events))
+	}
+}
+
+func TestWithSpanEvents(t *testing.T) {
+	ctx, rec := startSpan(t)
+	var called []string
+	l, _ := logtest.NewCapture(
+		logger.WithEntryCallback(func(ctx context.Context, entry logger.Entry) { called = append(called, entry.Message()) }),
+		otellog.WithOTel(otellog.WithSpanEvents(logger.LevelInfo)),
+	)
+	l.Debug(ctx, "below the level")
+	l.Info(ctx, "hello")
+	l.Error(ctx, errors.New("boom"))
+
+	if len(called) != 3 {
+		t.Errorf("callback got %q, want all the entries kept with the events", called)
+	}
+	rec.Started()[0].End()
+	span := rec.Ended()[0]
+	events := span.Events()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want those of the entries at LevelInfo or above", len(events))
+	}
+	if events[0].Name != "log" || !hasAttr(events[0].Attributes, "log.severity", "INFO") || !hasAttr(events[0].Attributes, "log.message", "hello") {
+		t.Errorf("events[0] = %+v, want the log event of the entry", events[0])
+	}
+	if events[1].Name != "exception" || !hasAttr(events[1].Attributes, "exception.message", "boom") ||
+		!hasAttr(events[1].Attributes, "log.severity", "ERROR") {
+		t.Errorf("events[1] = %+v, want the error recorded with the severity", events[1])
+	}
+	if st := span.Status(); st.Code != codes.Error {
+		t.Errorf("status = %+v, want codes.Error for the error report", st)
+	}
+}
+
+func TestWithSpanEventsWithoutErrorReport(t *testing.T) {
+	ctx, rec := startSpan(t)
+	l, _ := logtest.NewCapture(otellog.WithOTel(otellog.WithSpanEvents(logger.LevelDefault)))
+	l.Warn(ctx, "careful")
+
+	rec.Started()[0].End()
+	span := rec.Ended()[0]
+	if len(span.Events()) != 1 {
+		t.Errorf("got %d events, want 1", len(span.Events()))
+	}
+	if st := span.Status(); st.Code != codes.Unset {
+		t.Errorf("status = %+v, want it unset without the error report", st)
+	}
+}
+
+func hasAttr(attrs []attribute.KeyValue, key, value string) bool {
+	for _, kv := range attrs {
+		if string(kv.Key) == key {
+			return kv.Value.AsString() == value
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"context"
+	"runtime"
+	"strings"
+)
+
+// Recover recovers from a panic and writes it at LevelCritical with the error report.
+// The stack trace is written as the "stack_trace" field up to the depth set by WithStackDepth,
+// which Error Reporting uses to group the errors.
+// It must be deferred directly, like `defer l.Recover(ctx)`. It does nothing when there is no panic.
+func (l *Logger) Recover(ctx context.Context, opts ...EntryOption) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	entry := l.panicEntry(v, opts)
+	entry.skipCaller += panicFrames()
+	l.write(ctx, entry)
+}
+
+// LogPanic writes the value recovered from a panic as Recover does, for the callers recovering by themselves,
+// e.g. `if v := recover(); v != nil { l.LogPanic(ctx, v); ... }`. It does nothing when recovered is nil.
+// Unlike Recover, the source location is the caller of LogPanic.
+func (l *Logger) LogPanic(ctx context.Context, recovered any, opts ...EntryOption) {
+	if recovered == nil {
+		return
+	}
+	l.write(ctx, l.panicEntry(recovered, opts))
+}
+
+// WithPanicFormatter sets the function to format the value recovered by Logger.Recover and Logger.LogPanic
+// into the message, which is fmt.Sprintf("%v", v) by default.
+// e.g. it can format the errors in detail, or the structs with fmt.Sprintf("%+v", v).
+func WithPanicFormatter(f func(any) string) LoggerOption {
+	return func(l *Logger) {
+		l.formatPanic = f
+	}
+}
+
+func (l *Logger) panicEntry(v any, opts []EntryOption) Entry {
+	// the stack trace is captured from the function which panicked like that of Logger.Error, up to the stack depth
+	entry := NewEntry(LevelCritical, "panic: "+l.formatPanic(v), append([]EntryOption{WithStackTrace(true)}, opts...)...)
+	entry.errorReport = true
+	return entry
+}
+
+// panicFrames returns the number of the frames of the runtime between the deferred function and
+// the function which panicked, so that the source location points to the latter.
+func panicFrames() int {
+	// 0: runtime.Callers, 1: panicFrames, 2: Logger.Recover, 3: runtime.gopanic, ...
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	count := 0
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") || !more {
+			return count
+		}
+		count++
+	}
+}
@@ -0,0 +1,91 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func panicking(l *logger.Logger) {
+	defer l.Recover(context.Background())
+	panic("boom")
+}
+
+func TestRecoverStackTrace(t *testing.T) {
+	l, capture := logtest.NewCapture(
+		logger.WithPayloadGroup("payload"),
+		logger.WithMaxStringBytes(16),
+		logger.WithDefaultStackDepth(1),
+	)
+
+	panicking(l)
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if !e.ErrorReport || e.Message != "panic: boom" {
+		t.Errorf("entry = %+v, want the error report of the panic", e)
+	}
+	stack, _ := e.Attrs[logger.KeyStackTrace].(string)
+	if !strings.HasPrefix(stack, "panic: boom\n\ngoroutine 1 [running]:\ngithub.com/ebi-yade/osuite/logger_test.panicking(") {
+		t.Errorf("stack_trace = %q, want the one from the panicking function", stack)
+	}
+	if !strings.Contains(stack, "more frames") {
+		t.Errorf("stack_trace = %q, want the one limited to the depth", stack)
+	}
+	if _, ok := e.Attrs[logger.KeyContext]; ok {
+		t.Errorf("context = %v, want none with the stack trace", e.Attrs[logger.KeyContext])
+	}
+}
+
+type panicValue struct {
+	Code int
+}
+
+func TestPanicFormatter(t *testing.T) {
+	values := []any{"boom", errors.New("failed"), panicValue{Code: 42}}
+	for _, tt := range []struct {
+		name string
+		opts []logger.LoggerOption
+		want []string
+	}{
+		{
+			name: "default",
+			want: []string{"panic: boom", "panic: failed", "panic: {42}"},
+		},
+		{
+			name: "custom",
+			opts: []logger.LoggerOption{logger.WithPanicFormatter(func(v any) string {
+				if err, ok := v.(error); ok {
+					return "error: " + err.Error()
+				}
+				return fmt.Sprintf("%+v", v)
+			})},
+			want: []string{"panic: boom", "panic: error: failed", "panic: {Code:42}"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l, capture := logtest.NewCapture(tt.opts...)
+			for _, v := range values {
+				l.LogPanic(context.Background(), v)
+			}
+
+			entries := capture.Entries()
+			if len(entries) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d", len(entries), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if entries[i].Message != want {
+					t.Errorf("message of %#v = %q, want %q", values[i], entries[i].Message, want)
+				}
+			}
+		})
+	}
+}
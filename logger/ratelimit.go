@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// WithErrorRateLimit limits the entries at LevelError or above to max per window for each message,
+// to avoid flooding Error Reporting when the same error occurs repeatedly.
+// The suppressed entries are summarized at the end of the window by an entry carrying
+// the attributes of the first suppressed one and the number of the suppressed entries in the "rate_limit" group.
+// Each suppressed entry is also passed to the callback of WithDroppedEntryCallback with DropReasonThrottled.
+func WithErrorRateLimit(window time.Duration, max int) LoggerOption {
+	return func(l *Logger) {
+		l.limiter = &rateLimiter{
+			window: window,
+			max:    max,
+			emit:   l.emit,
+		}
+	}
+}
+
+type rateLimitBucket struct {
+	count      int
+	suppressed int
+	first      slog.Record // the first suppressed record
+}
+
+type rateLimiter struct {
+	window time.Duration
+	max    int
+	emit   func(context.Context, slog.Record)
+
+	mu      sync.Mutex
+	order   []string
+	buckets map[string]*rateLimitBucket
+}
+
+// suppress counts the record and reports whether it exceeds the limit of the current window.
+func (rl *rateLimiter) suppress(r slog.Record) bool {
+	if r.Level < LevelError {
+		return false
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.buckets == nil {
+		rl.buckets = make(map[string]*rateLimitBucket)
+		time.AfterFunc(rl.window, rl.flush)
+	}
+	b, ok := rl.buckets[r.Message]
+	if !ok {
+		b = &rateLimitBucket{}
+		rl.buckets[r.Message] = b
+		rl.order = append(rl.order, r.Message)
+	}
+	b.count++
+	if b.count <= rl.max {
+		return false
+	}
+	if b.suppressed == 0 {
+		b.first = r.Clone()
+	}
+	b.suppressed++
+	return true
+}
+
+// flush emits the summaries of the suppressed records of the current window.
+func (rl *rateLimiter) flush() {
+	rl.mu.Lock()
+	order, buckets := rl.order, rl.buckets
+	rl.order, rl.buckets = nil, nil
+	rl.mu.Unlock()
+
+	for _, msg := range order {
+		b := buckets[msg]
+		if b.suppressed == 0 {
+			continue
+		}
+		b.first.AddAttrs(slog.Group("rate_limit",
+			slog.Int("suppressed", b.suppressed),
+			slog.String("window", rl.window.String()),
+		))
+		rl.emit(context.Background(), b.first)
+	}
+}
@@ -0,0 +1,91 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestErrorRateLimitDropCallback(t *testing.T) {
+	var reasons []logger.DropReason
+	l, capture := logtest.NewCapture(
+		logger.WithErrorRateLimit(time.Hour, 1),
+		logger.WithDroppedEntryCallback(func(_ context.Context, _ logger.Entry, reason logger.DropReason) {
+			reasons = append(reasons, reason)
+		}),
+	)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		l.Error(ctx, errors.New("failed"))
+	}
+
+	if got := len(capture.Entries()); got != 1 {
+		t.Errorf("got %d entries, want 1", got)
+	}
+	if len(reasons) != 2 || reasons[0] != logger.DropReasonThrottled || reasons[1] != logger.DropReasonThrottled {
+		t.Errorf("reasons = %v, want 2 of %v", reasons, logger.DropReasonThrottled)
+	}
+}
+
+func TestErrorRateLimitSummary(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithErrorRateLimit(200*time.Millisecond, 2))
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		l.Error(ctx, errors.New("failed"))
+	}
+	l.Error(ctx, errors.New("other"))
+	l.Warn(ctx, "failed") // not limited below LevelError
+	if got := len(capture.Entries()); got != 4 {
+		t.Fatalf("got %d entries in the window, want 4", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(capture.Entries()) < 5 {
+		if time.Now().After(deadline) {
+			t.Fatal("the summary is not emitted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	entries := capture.Entries()
+	summary := entries[len(entries)-1]
+	rateLimit, _ := summary.Attrs["rate_limit"].(map[string]any)
+	if summary.Message != "failed" || rateLimit["suppressed"] != float64(3) {
+		t.Errorf("summary = %+v, want the one of 3 suppressed entries", summary)
+	}
+}
+
+func TestErrorRateLimitWriteBatch(t *testing.T) {
+	var reasons []logger.DropReason
+	l, capture := logtest.NewCapture(
+		logger.WithErrorRateLimit(time.Hour, 1),
+		logger.WithDroppedEntryCallback(func(_ context.Context, _ logger.Entry, reason logger.DropReason) {
+			reasons = append(reasons, reason)
+		}),
+	)
+	ctx := context.Background()
+
+	l.Error(ctx, errors.New("failed"))
+	l.WriteBatch(ctx, []logger.Entry{
+		logger.NewEntry(logger.LevelError, "failed"),
+		logger.NewEntry(logger.LevelError, "other"),
+		logger.NewEntry(logger.LevelError, "other"),
+		logger.NewEntry(logger.LevelInfo, "failed"),
+	})
+
+	var got []string
+	for _, e := range capture.Entries() {
+		got = append(got, e.Message)
+	}
+	if len(got) != 3 || got[0] != "failed" || got[1] != "other" || got[2] != "failed" {
+		t.Errorf("messages = %q, want the batch limited with the entries before it", got)
+	}
+	if len(reasons) != 2 || reasons[0] != logger.DropReasonThrottled || reasons[1] != logger.DropReasonThrottled {
+		t.Errorf("reasons = %v, want 2 of %v", reasons, logger.DropReasonThrottled)
+	}
+}
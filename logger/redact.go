@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"log/slog"
+)
+
+// WithRedactor sets the function to rewrite the attributes, typically to mask sensitive values like:
+//
+//	logger.WithRedactor(func(groups []string, a slog.Attr) slog.Attr {
+//		if a.Key == "token" {
+//			return slog.String(a.Key, "[REDACTED]")
+//		}
+//		return a
+//	})
+//
+// It runs after the built-in attributes are rewritten into the special fields.
+// It is never called for the special fields such as severity, message, sourceLocation and trace,
+// nor for their members, so that it cannot break them.
+func WithRedactor(f func(groups []string, a slog.Attr) slog.Attr) LoggerOption {
+	return func(l *Logger) {
+		l.redactor = f
+	}
+}
+
+var reservedKeys = map[string]bool{
+	KeyTime:           true,
+	KeySeverity:       true,
+	KeyMessage:        true,
+	KeySourceLocation: true,
+	KeyTrace:          true,
+	KeySpanID:         true,
+	KeyTraceSampled:   true,
+	KeyInsertID:       true,
+	KeyLabels:         true,
+	KeyHTTPRequest:    true,
+	KeyOperation:      true,
+	KeyStackTrace:     true,
+	KeyChecksum:       true,
+	KeyType:           true,
+	KeyServiceContext: true,
+	KeyContext:        true,
+}
+
+// redact applies the redactor to the attribute unless it is or belongs to a special field.
+func (l *Logger) redact(groups []string, a slog.Attr) slog.Attr {
+	if l.redactor == nil {
+		return a
+	}
+	if len(groups) > 0 && reservedKeys[groups[0]] || len(groups) == 0 && reservedKeys[a.Key] {
+		return a
+	}
+	return l.redactor(groups, a)
+}
@@ -0,0 +1,193 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// WithRetry makes the logger retry the handler up to maxAttempts in total when it fails to write an entry.
+// The wait before each retry starts at backoff and doubles every time, and it ends early when ctx is done.
+// It is safe to retry because the uniqueness of the entry is guaranteed by time and insertId.
+// When all the attempts fail, the error is passed to the function set by WithErrorHandler.
+// Use WithRetryPolicy for the jitter and the other settings.
+func WithRetry(maxAttempts int, backoff time.Duration) LoggerOption {
+	return WithRetryPolicy(RetryPolicy{MaxAttempts: maxAttempts, InitialBackoff: backoff})
+}
+
+// RetryPolicy configures the retry of the handler failing to write an entry.
+type RetryPolicy struct {
+	// MaxAttempts is the number of the attempts in total, including the first one. 0 or 1 disables the retry.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait before each retry. 0 means no cap.
+	MaxBackoff time.Duration
+	// Multiplier is the factor of the wait of each retry to the previous one. 0 means 2.
+	Multiplier float64
+	// Jitter randomizes each wait by the fraction at most, e.g. 0.2 for ±20%,
+	// so that the loggers failing at the same time do not retry at the same time.
+	Jitter float64
+	// OnExhausted is called with the record and the last error when all the attempts fail,
+	// e.g. to save the record elsewhere. It is called before the function set by WithErrorHandler,
+	// and not called when the retry ends early because ctx is done.
+	OnExhausted func(ctx context.Context, r slog.Record, err error)
+}
+
+// WithRetryPolicy makes the logger retry the handler following the policy when it fails to write an entry.
+// The wait grows exponentially, and it ends early when ctx is done. All the attempts write the same record
+// with the same insertId, so that Cloud Logging deduplicates the entry written more than once.
+// When all the attempts fail, the error is passed to the function set by WithErrorHandler.
+//
+// The writes of the other goroutines are not blocked during the waits, so the retried entry may be written
+// after the entries logged later, including the other entries of its Logger.WriteBatch.
+// With WithHashChain, however, the writes wait for the retries, since the order must follow the chain.
+func WithRetryPolicy(p RetryPolicy) LoggerOption {
+	return func(l *Logger) {
+		if p.Multiplier == 0 {
+			p.Multiplier = 2
+		}
+		l.retry = p
+	}
+}
+
+// WithErrorHandler sets the function called with the error of the handler failing to write an entry,
+// including the errors of the uploads by the client of NewWithClient unless its OnError is already set.
+// By default, the error is printed to os.Stderr.
+//
+// f may log through the logger only with the given context: an entry failing during f is not passed to f again
+// but printed to os.Stderr, to avoid the infinite recursion.
+func WithErrorHandler(f func(ctx context.Context, err error)) LoggerOption {
+	return func(l *Logger) {
+		l.onError = f
+	}
+}
+
+// backoff returns the base wait of the next retry and the jittered wait of the current one, given its base wait.
+func (p RetryPolicy) backoff(wait time.Duration) (next, jittered time.Duration) {
+	jittered = wait
+	if p.Jitter > 0 {
+		jittered = time.Duration(float64(wait) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	}
+	next = time.Duration(float64(wait) * p.Multiplier)
+	if p.MaxBackoff > 0 && next > p.MaxBackoff {
+		next = p.MaxBackoff
+	}
+	return next, jittered
+}
+
+// exhaustedError is the error of the record which failed all the attempts of the retry.
+type exhaustedError struct {
+	r   slog.Record
+	err error
+}
+
+func (e *exhaustedError) Error() string { return e.err.Error() }
+func (e *exhaustedError) Unwrap() error { return e.err }
+
+type handlingErrorKey struct{}
+
+// handleError passes the error to the error handler unless the failed entry was logged by the error handler itself.
+func (l *Logger) handleError(ctx context.Context, err error) {
+	if ctx.Value(handlingErrorKey{}) != nil {
+		printHandlerError(ctx, err)
+		return
+	}
+	ctx = context.WithValue(ctx, handlingErrorKey{}, true)
+	var exhausted *exhaustedError
+	if l.retry.OnExhausted != nil && errors.As(err, &exhausted) {
+		l.retry.OnExhausted(ctx, exhausted.r, exhausted.err)
+	}
+	l.onError(ctx, err)
+}
+
+func printHandlerError(_ context.Context, err error) {
+	fmt.Fprintf(os.Stderr, "osuite/logger: failed to write the entry: %v\n", err)
+}
+
+// retryUnlocked retries the record which failed the first attempt following the retry policy.
+// emitMu is held only during each attempt, so that the waits don't block the other goroutines.
+func (l *Logger) retryUnlocked(ctx context.Context, r slog.Record, err error) error {
+	err = l.retryAfter(ctx, r, err, func() error {
+		l.emitMu.Lock()
+		defer l.emitMu.Unlock()
+		return l.handleOnce(ctx, r)
+	})
+	if err == nil {
+		l.handled(r)
+	}
+	return err
+}
+
+// retryAfter calls attempt again following the retry policy, given the error of the first attempt.
+func (l *Logger) retryAfter(ctx context.Context, r slog.Record, err error, attempt func() error) error {
+	if l.retry.MaxAttempts <= 1 || !retryable(err) {
+		return err
+	}
+	next := l.retry.InitialBackoff
+	for i := 1; err != nil && retryable(err) && i < l.retry.MaxAttempts; i++ {
+		var wait time.Duration
+		next, wait = l.retry.backoff(next)
+		if cerr := l.sleep(ctx, wait); cerr != nil {
+			return fmt.Errorf("%w (retry canceled: %w)", err, cerr)
+		}
+		err = attempt()
+	}
+	if err != nil && retryable(err) {
+		return &exhaustedError{r: r, err: err}
+	}
+	return err
+}
+
+// sleep waits for d, returning the error of ctx if it is done earlier.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryable reports whether the record failing with err can be written again without duplicating it.
+func retryable(err error) bool {
+	var partial *partialWriteError
+	return !errors.As(err, &partial)
+}
+
+// WithWriteTimeout makes the logger abandon the write of an entry taking longer than timeout,
+// and pass the error to the function set by WithErrorHandler. It is disabled by default.
+// Since an io.Writer cannot be interrupted, the abandoned write keeps running on its own goroutine
+// until it returns, and the following writes wait for it inside the handler; each of them times out as well.
+func WithWriteTimeout(timeout time.Duration) LoggerOption {
+	return func(l *Logger) {
+		l.writeTimeout = timeout
+	}
+}
+
+// handleOnce passes the record to the handler, giving up after the write timeout.
+func (l *Logger) handleOnce(ctx context.Context, r slog.Record) error {
+	if l.writeTimeout <= 0 {
+		return l.handler.Handle(ctx, r)
+	}
+
+	// the cancellation of the caller does not abandon the write; only the timeout does
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.writeTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- l.handler.Handle(ctx, r)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("write timed out after %s: %w", l.writeTimeout, ctx.Err())
+	}
+}
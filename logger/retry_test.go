@@ -0,0 +1,176 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+)
+
+// failingHandler fails the records of the message and sends the messages of the handled ones.
+type failingHandler struct {
+	fail    string
+	handled chan string
+}
+
+func (h *failingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *failingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.handled <- r.Message
+	if r.Message == h.fail {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+func (h *failingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *failingHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestRetryDoesNotBlockOthers(t *testing.T) {
+	h := &failingHandler{fail: "first", handled: make(chan string, 8)}
+	errs := make(chan error, 1)
+	l := logger.New(nil, "p", logger.LevelInfo,
+		logger.WithHandler(h),
+		logger.WithRetry(3, time.Hour),
+		logger.WithErrorHandler(func(_ context.Context, err error) { errs <- err }),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go l.Info(ctx, "first")
+	if got := <-h.handled; got != "first" {
+		t.Fatalf("handled %q, want first", got)
+	}
+	go l.Info(context.Background(), "second")
+	select {
+	case got := <-h.handled:
+		if got != "second" {
+			t.Fatalf("handled %q, want second", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the write is blocked by the wait of the retry")
+	}
+
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want the canceled retry", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the retry is not canceled")
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestErrorHandler(t *testing.T) {
+	var l *logger.Logger
+	var errs []error
+	l = logger.New(failingWriter{}, "p", logger.LevelInfo, logger.WithErrorHandler(func(ctx context.Context, err error) {
+		errs = append(errs, err)
+		l.Info(ctx, "failed to log") // fails as well, which must not be passed to the handler again
+	}))
+
+	l.Info(context.Background(), "hello")
+
+	if len(errs) != 1 || errs[0].Error() != "disk full" {
+		t.Errorf("errs = %v, want the error of the writer once", errs)
+	}
+}
+
+// attemptWriter fails every write with the number of the attempt.
+type attemptWriter struct{ attempts int }
+
+func (w *attemptWriter) Write([]byte) (int, error) {
+	w.attempts++
+	return 0, fmt.Errorf("attempt %d", w.attempts)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	want := []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 900 * time.Millisecond, time.Second, time.Second}
+	for _, jitter := range []float64{0, 0.2} {
+		w := &attemptWriter{}
+		var waits []time.Duration
+		var exhausted []error
+		var records []string
+		var errs []error
+		l := logger.New(w, "p", logger.LevelInfo,
+			logger.WithRetryPolicy(logger.RetryPolicy{
+				MaxAttempts:    6,
+				InitialBackoff: 100 * time.Millisecond,
+				MaxBackoff:     time.Second,
+				Multiplier:     3,
+				Jitter:         jitter,
+				OnExhausted: func(_ context.Context, r slog.Record, err error) {
+					records = append(records, r.Message)
+					exhausted = append(exhausted, err)
+				},
+			}),
+			logger.WithRetrySleep(func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}),
+			logger.WithErrorHandler(func(_ context.Context, err error) { errs = append(errs, err) }),
+		)
+		l.Info(context.Background(), "hello")
+
+		if w.attempts != 6 {
+			t.Errorf("jitter %v: got %d attempts, want 6", jitter, w.attempts)
+		}
+		if len(waits) != len(want) {
+			t.Fatalf("jitter %v: waits = %v, want %v", jitter, waits, want)
+		}
+		for i, d := range waits {
+			lo := time.Duration(float64(want[i]) * (1 - jitter))
+			hi := time.Duration(float64(want[i]) * (1 + jitter))
+			if d < lo || d > hi {
+				t.Errorf("jitter %v: waits[%d] = %v, want within [%v, %v]", jitter, i, d, lo, hi)
+			}
+		}
+		if len(exhausted) != 1 || exhausted[0].Error() != "attempt 6" || records[0] != "hello" {
+			t.Errorf("jitter %v: OnExhausted got %v of %q, want the last error once", jitter, exhausted, records)
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), "attempt 6") {
+			t.Errorf("jitter %v: errors = %v, want the last one after OnExhausted", jitter, errs)
+		}
+	}
+}
+
+func TestRetryPolicyCanceled(t *testing.T) {
+	w := &attemptWriter{}
+	exhausted := 0
+	var errs []error
+	ctx, cancel := context.WithCancel(context.Background())
+	l := logger.New(w, "p", logger.LevelInfo,
+		logger.WithRetryPolicy(logger.RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+			OnExhausted:    func(context.Context, slog.Record, error) { exhausted++ },
+		}),
+		logger.WithRetrySleep(func(ctx context.Context, _ time.Duration) error {
+			if w.attempts == 2 {
+				cancel()
+			}
+			return ctx.Err()
+		}),
+		logger.WithErrorHandler(func(_ context.Context, err error) { errs = append(errs, err) }),
+	)
+	l.Info(ctx, "hello")
+
+	if w.attempts != 2 {
+		t.Errorf("got %d attempts, want 2 before the cancel", w.attempts)
+	}
+	if exhausted != 0 {
+		t.Errorf("OnExhausted is called %d times, want none for the canceled retry", exhausted)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("errors = %v, want the canceled retry", errs)
+	}
+}
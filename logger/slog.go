@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+)
+
+// Slog returns a *slog.Logger which writes through the logger,
+// for the libraries which accept *slog.Logger.
+// The entries get the same enrichment as the methods of Logger, such as the trace and the insertId.
+//
+// The levels of log/slog are mapped to the severities: slog.LevelDebug to LevelDebug,
+// slog.LevelInfo to LevelInfo, slog.LevelWarn to LevelWarning and slog.LevelError to LevelError.
+// The levels at or above LevelDebug are regarded as the severities of this package as they are.
+// The entries at LevelError or above are reported as errors, or at the level set by WithErrorReportMinLevel.
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(NewSlogHandler(l))
+}
+
+// NewSlogHandler returns the slog.Handler writing through the logger, for the libraries which accept a slog.Handler.
+// It is the same as l.Slog().Handler(), and maps the levels as Logger.Slog does.
+func NewSlogHandler(l *Logger) slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+// NewGCPHandler returns a slog.Handler writing the records to w in the format of Cloud Logging,
+// e.g. to use it with slog.SetDefault for the codebases using log/slog directly.
+// The records get the same enrichment as the methods of Logger, such as the trace and the insertId from the context.
+// The minimum level is LevelInfo, following log/slog; use New(...).Slog().Handler() to choose another one.
+// The time of the record is kept as the timestamp, and the clock set by WithClock is used only for the records without it.
+func NewGCPHandler(w io.Writer, projectID string, opts ...LoggerOption) slog.Handler {
+	return NewSlogHandler(New(w, projectID, LevelInfo, opts...))
+}
+
+// severityLevel maps the level of log/slog to the severity level.
+func severityLevel(level slog.Level) slog.Level {
+	switch {
+	case level >= LevelDebug:
+		return level
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarning
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	default:
+		return LevelDebug
+	}
+}
+
+// groupOrAttrs holds either a group name or attributes given to slog.Handler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// buildAttrs puts attrs of the record into the groups and after the attributes given so far.
+func buildAttrs(goas []groupOrAttrs, attrs []slog.Attr) []slog.Attr {
+	for i := len(goas) - 1; i >= 0; i-- {
+		if goas[i].group == "" {
+			attrs = append(slices.Clip(goas[i].attrs), attrs...)
+			continue
+		}
+		if len(attrs) > 0 {
+			attrs = []slog.Attr{slog.Group(goas[i].group, anyAttrs(attrs)...)}
+		}
+	}
+	return attrs
+}
+
+type slogHandler struct {
+	logger *Logger
+	goas   []groupOrAttrs
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.Enabled(ctx, severityLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	level := severityLevel(r.Level)
+	entry := NewEntry(level, r.Message, WithAttrs(buildAttrs(h.goas, attrs)...))
+	entry.errorReport = level >= h.logger.errorReportLevel
+	entry.time = r.Time // e.g. the time of logging for the records written later by AsyncHandler
+	pc := r.PC
+	if !h.logger.needsSource(entry) {
+		pc = 0
+	}
+	if h.logger.sampled(ctx, entry) {
+		h.logger.handle(ctx, entry, pc)
+	}
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: slices.Clone(attrs)})
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *slogHandler) with(goa groupOrAttrs) *slogHandler {
+	return &slogHandler{
+		logger: h.logger,
+		goas:   append(slices.Clip(h.goas), goa),
+	}
+}
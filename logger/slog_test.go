@@ -0,0 +1,36 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestGCPHandlerRecordTime(t *testing.T) {
+	clock := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := &logtest.Capture{}
+	h := logger.NewGCPHandler(c, "p", logger.WithClock(func() time.Time { return clock }))
+	ctx := context.Background()
+
+	logged := time.Date(2023, 12, 31, 23, 59, 59, 123456789, time.FixedZone("JST", 9*60*60))
+	if err := h.Handle(ctx, slog.NewRecord(logged, slog.LevelInfo, "replayed", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelInfo, "without time", 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := c.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []string{"2023-12-31T14:59:59.123456789Z", "2024-01-02T03:04:05.000000000Z"} {
+		if got := entries[i].Attrs[logger.KeyTime]; got != want {
+			t.Errorf("time of %q = %v, want %v", entries[i].Message, got, want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// normalizeSpanID returns the span ID in the 16-digit hex form expected by Cloud Logging.
+// A decimal span ID such as the one of X-Cloud-Trace-Context is converted to hex.
+// It returns false if the span ID is malformed, and then the logger warns about it once to os.Stderr.
+func (l *Logger) normalizeSpanID(spanID string) (string, bool) {
+	if len(spanID) == 16 && isHex(spanID) {
+		return spanID, true
+	}
+	if n, err := strconv.ParseUint(spanID, 10, 64); err == nil {
+		return fmt.Sprintf("%016x", n), true
+	}
+	l.spanIDWarning.Do(func() {
+		fmt.Fprintf(os.Stderr, "osuite/logger: dropped the malformed span ID %q, which must be 16 hex digits\n", spanID)
+	})
+	return "", false
+}
+
+func isHex(s string) bool {
+	for _, c := range []byte(s) {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
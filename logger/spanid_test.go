@@ -0,0 +1,37 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestSpanIDNormalization(t *testing.T) {
+	for _, tt := range []struct {
+		name, spanID, want string
+	}{
+		{name: "hex", spanID: "00f067aa0ba902b7", want: "00f067aa0ba902b7"},
+		{name: "decimal", spanID: "123", want: "000000000000007b"},
+		{name: "span context", spanID: "4bf92f3577b34da6a3ce929d0e0e4736/00f067aa0ba902b7", want: ""},
+		{name: "too short hex", spanID: "f067aa", want: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l, capture := logtest.NewCapture(
+				logger.WithTraceID(func(context.Context) string { return "trace" }),
+				logger.WithSpanID(func(context.Context) string { return tt.spanID }),
+			)
+
+			l.Info(context.Background(), "hello")
+
+			entries := capture.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if entries[0].SpanID != tt.want {
+				t.Errorf("spanId = %q, want %q", entries[0].SpanID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// WithSplitWriters makes New write the entries at threshold or above to errw, and the others to out,
+// instead of the io.Writer given to New, which is ignored then.
+// For example, WithSplitWriters(os.Stdout, os.Stderr, LevelError) writes the errors to stderr on Cloud Run.
+func WithSplitWriters(out, errw io.Writer, threshold slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.split = &splitWriters{out: out, err: errw, threshold: threshold}
+	}
+}
+
+type splitWriters struct {
+	out       io.Writer
+	err       io.Writer
+	threshold slog.Level
+}
+
+func (s *splitWriters) flush() error {
+	var errs []error
+	for _, w := range []io.Writer{s.out, s.err} {
+		if f, ok := w.(interface{ Flush() error }); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// splitHandler routes the records to the handlers by the level.
+type splitHandler struct {
+	low       slog.Handler
+	high      slog.Handler
+	threshold slog.Level
+}
+
+func (h *splitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.threshold {
+		return h.high.Enabled(ctx, level)
+	}
+	return h.low.Enabled(ctx, level)
+}
+
+func (h *splitHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.threshold {
+		return h.high.Handle(ctx, r)
+	}
+	return h.low.Handle(ctx, r)
+}
+
+func (h *splitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &splitHandler{low: h.low.WithAttrs(attrs), high: h.high.WithAttrs(attrs), threshold: h.threshold}
+}
+
+func (h *splitHandler) WithGroup(name string) slog.Handler {
+	return &splitHandler{low: h.low.WithGroup(name), high: h.high.WithGroup(name), threshold: h.threshold}
+}
@@ -0,0 +1,34 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestSplitWriters(t *testing.T) {
+	out, errw := &logtest.Capture{}, &logtest.Capture{}
+	l := logger.New(nil, "p", logger.LevelInfo, logger.WithSplitWriters(out, errw, logger.LevelError))
+	ctx := context.Background()
+
+	l.Warn(ctx, "warning")
+	l.Error(ctx, errors.New("error"))
+
+	for name, tt := range map[string]struct {
+		capture *logtest.Capture
+		want    string
+		level   slog.Level
+	}{
+		"out":  {capture: out, want: "warning", level: logger.LevelWarning},
+		"errw": {capture: errw, want: "error", level: logger.LevelError},
+	} {
+		entries := tt.capture.Entries()
+		if len(entries) != 1 || entries[0].Message != tt.want || entries[0].Level != tt.level {
+			t.Errorf("%s got %+v, want only the %s", name, entries, tt.want)
+		}
+	}
+}
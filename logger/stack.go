@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+const (
+	maxStackDepth     = 64
+	maxErrorChain     = 100
+	defaultStackDepth = 32
+)
+
+// errorStack returns the stack trace carried by the innermost error of the chain which has one.
+// For the errors wrapping multiple errors such as those of errors.Join, the first one with a stack trace is taken.
+func errorStack(err error) []uintptr {
+	visited := 0
+	return innermostStack(err, &visited)
+}
+
+func innermostStack(err error, visited *int) []uintptr {
+	if err == nil || *visited >= maxErrorChain {
+		return nil
+	}
+	*visited++
+	var inner []uintptr
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		inner = innermostStack(u.Unwrap(), visited)
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if inner = innermostStack(e, visited); inner != nil {
+				break
+			}
+		}
+	}
+	if inner != nil {
+		return inner
+	}
+	return stackTraceOf(err)
+}
+
+// stackTraceOf returns the result of the StackTrace method like the one of github.com/pkg/errors,
+// whose result is a slice of program counters. reflect is used to avoid depending on the package.
+func stackTraceOf(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	if st.Len() == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
+}
+
+// stackValue formats the stack trace of the entry lazily, so that the frames are symbolized by the handler
+// only when the stack trace is written, e.g. not for the entries aggregated or written by the console format.
+type stackValue struct {
+	entry Entry
+}
+
+func (v stackValue) LogValue() slog.Value {
+	return slog.StringValue(formatStack(v.entry, v.entry.stack))
+}
+
+// formatStack formats the stack in the format of Go panics, which Error Reporting parses.
+func formatStack(entry Entry, stack []uintptr) string {
+	msg := entry.msg
+	if entry.err != nil {
+		msg = entry.err.Error()
+	}
+
+	var b strings.Builder
+	b.WriteString(msg)
+	b.WriteString("\n\ngoroutine 1 [running]:\n")
+	frames := runtime.CallersFrames(stack)
+	written, omitted := 0, 0
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			if written < entry.stackDepth {
+				fmt.Fprintf(&b, "%s(...)\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+				written++
+			} else {
+				omitted++
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "... %d more frames\n", omitted)
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestStackDepth(t *testing.T) {
+	l, capture := logtest.NewCapture()
+	ctx := context.Background()
+
+	l.Error(ctx, errors.New("limited"), logger.WithStackDepth(2))
+	l.Error(ctx, errors.New("disabled"), logger.WithStackDepth(0))
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	stack, _ := entries[0].Attrs[logger.KeyStackTrace].(string)
+	if n := strings.Count(stack, "\n\t"); n != 2 {
+		t.Errorf("got %d frames in %q, want 2", n, stack)
+	}
+	if !strings.HasPrefix(stack, "limited\n\ngoroutine 1 [running]:\ngithub.com/ebi-yade/osuite/logger_test.TestStackDepth(") {
+		t.Errorf("stack_trace = %q, want the one from the test", stack)
+	}
+	if !strings.Contains(stack, " more frames\n") {
+		t.Errorf("stack_trace = %q, want the marker of the omitted frames", stack)
+	}
+	if st, ok := entries[1].Attrs[logger.KeyStackTrace]; ok {
+		t.Errorf("stack_trace = %q, want none for the depth of 0", st)
+	}
+}
+
+func TestDefaultStackDepth(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithDefaultStackDepth(0))
+	ctx := context.Background()
+
+	l.Error(ctx, errors.New("disabled"))
+	l.Error(ctx, errors.New("enabled"), logger.WithStackDepth(1))
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if st, ok := entries[0].Attrs[logger.KeyStackTrace]; ok {
+		t.Errorf("stack_trace = %q, want none for the default depth of 0", st)
+	}
+	if stack, _ := entries[1].Attrs[logger.KeyStackTrace].(string); strings.Count(stack, "\n\t") != 1 {
+		t.Errorf("stack_trace = %q, want 1 frame", stack)
+	}
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// traceHeader is the trace context parsed from the headers of the incoming request.
+type traceHeader struct {
+	traceID string
+	spanID  string
+	sampled bool
+}
+
+type traceHeaderKey struct{}
+
+// ContextWithTraceHeader returns a copy of ctx carrying the trace context of the headers,
+// which the logger of WithTraceHeader emits. The W3C traceparent header takes precedence over
+// X-Cloud-Trace-Context of Google Cloud. ctx is returned as it is when neither of them is valid.
+func ContextWithTraceHeader(ctx context.Context, h http.Header) context.Context {
+	th, ok := parseTraceparent(h.Get("traceparent"))
+	if !ok {
+		th, ok = parseCloudTraceContext(h.Get("X-Cloud-Trace-Context"))
+	}
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, traceHeaderKey{}, th)
+}
+
+// WithTraceHeader sets the functions to get the traceID, the spanID and the sampled flag
+// from the trace context carried by ctx of ContextWithTraceHeader.
+// Nothing is emitted for the contexts without it.
+func WithTraceHeader() LoggerOption {
+	return func(l *Logger) {
+		l.getTraceID = func(ctx context.Context) string {
+			return traceHeaderFromContext(ctx).traceID
+		}
+		l.getSpanID = func(ctx context.Context) string {
+			return traceHeaderFromContext(ctx).spanID
+		}
+		l.getTraceSampled = func(ctx context.Context) bool {
+			return traceHeaderFromContext(ctx).sampled
+		}
+	}
+}
+
+func traceHeaderFromContext(ctx context.Context) traceHeader {
+	th, _ := ctx.Value(traceHeaderKey{}).(traceHeader)
+	return th
+}
+
+// parseTraceparent parses the header in the format of "version-traceid-parentid-flags",
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func parseTraceparent(s string) (traceHeader, bool) {
+	parts := strings.Split(s, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || parts[0] == "00" && len(parts) != 4 {
+		return traceHeader{}, false
+	}
+	traceID, spanID, flags := parts[1], parts[2], parts[3]
+	if !isHexID(traceID, 32) || !isHexID(spanID, 16) || len(flags) != 2 || !isHex(flags) {
+		return traceHeader{}, false
+	}
+	b, _ := hex.DecodeString(flags)
+	return traceHeader{traceID: traceID, spanID: spanID, sampled: b[0]&1 == 1}, true
+}
+
+// parseCloudTraceContext parses the header in the format of "TRACE_ID/SPAN_ID;o=OPTIONS",
+// where the span ID is decimal and the part after the trace ID is optional.
+func parseCloudTraceContext(s string) (traceHeader, bool) {
+	s, options, _ := strings.Cut(s, ";")
+	traceID, spanID, _ := strings.Cut(s, "/")
+	if !isHexID(traceID, 32) {
+		return traceHeader{}, false
+	}
+	if n, err := strconv.ParseUint(spanID, 10, 64); err == nil {
+		// convert it here, since a decimal of 16 digits can't be told from the hex one later
+		spanID = fmt.Sprintf("%016x", n)
+	}
+	return traceHeader{traceID: traceID, spanID: spanID, sampled: options == "o=1"}, true
+}
+
+// isHexID reports whether s is the hex of n digits which is not all zero, as the IDs must be.
+func isHexID(s string, n int) bool {
+	return len(s) == n && isHex(s) && strings.Trim(s, "0") != ""
+}
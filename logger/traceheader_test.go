@@ -0,0 +1,35 @@
+package logger_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestCloudTraceContextSpanID(t *testing.T) {
+	for _, tt := range []struct {
+		name, spanID, want string
+	}{
+		{name: "short decimal", spanID: "123", want: "000000000000007b"},
+		{name: "16-digit decimal", spanID: "1234567890123456", want: "000462d53c8abac0"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l, capture := logtest.NewCapture(logger.WithTraceHeader())
+			h := http.Header{}
+			h.Set("X-Cloud-Trace-Context", "4bf92f3577b34da6a3ce929d0e0e4736/"+tt.spanID+";o=1")
+
+			l.Info(logger.ContextWithTraceHeader(context.Background(), h), "hello")
+
+			entries := capture.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if entries[0].SpanID != tt.want {
+				t.Errorf("spanId = %q, want %q", entries[0].SpanID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"unicode/utf8"
+)
+
+// defaultMaxStringBytes keeps an entry with a few large strings under the limit of Cloud Logging, 256KB per entry.
+const defaultMaxStringBytes = 64 * 1024
+
+// WithTruncatedString adds the string attribute truncated to max bytes, e.g. the body of a request.
+// The truncated value ends with the marker "…(truncated N bytes)", and never splits a multibyte character.
+// max <= 0 leaves the value to the limit set by WithMaxStringBytes.
+func WithTruncatedString(key, value string, max int) EntryOption {
+	if max <= 0 {
+		return WithAttrs(slog.String(key, value))
+	}
+	return WithAttrs(slog.Any(key, truncatedString(truncateString(value, max))))
+}
+
+// truncatedString is the value truncated by WithTruncatedString,
+// which WithMaxStringBytes does not truncate again not to repeat the marker.
+type truncatedString string
+
+// WithMaxStringBytes sets the maximum number of bytes of the string attributes of every entry,
+// including those in groups, which is 64KiB by default. The longer values are truncated as WithTruncatedString does.
+// The fields of Cloud Logging such as the message and the stack trace are not truncated. 0 disables the limit.
+func WithMaxStringBytes(max int) LoggerOption {
+	return func(l *Logger) {
+		l.maxStringBytes = max
+	}
+}
+
+// truncateString truncates s to max bytes on the boundary of UTF-8 characters and appends the marker.
+func truncateString(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s…(truncated %d bytes)", s[:n], len(s)-n)
+}
+
+// truncateAttr truncates the string values of the resolved attr, including those in groups.
+// It reports whether any value is truncated, not to copy the groups without the long values.
+func truncateAttr(a slog.Attr, max int) (slog.Attr, bool) {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if s := a.Value.String(); len(s) > max {
+			a.Value = slog.StringValue(truncateString(s, max))
+			return a, true
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+		var truncated []slog.Attr
+		for i, ga := range group {
+			if ga, ok := truncateAttr(ga, max); ok {
+				if truncated == nil {
+					truncated = slices.Clone(group)
+				}
+				truncated[i] = ga
+			}
+		}
+		if truncated != nil {
+			a.Value = slog.GroupValue(truncated...)
+			return a, true
+		}
+	}
+	return a, false
+}
@@ -0,0 +1,62 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestTruncatedString(t *testing.T) {
+	l, capture := logtest.NewCapture()
+
+	l.Info(context.Background(), "hello",
+		logger.WithTruncatedString("body", "あいう", 4), // 3 bytes each
+		logger.WithTruncatedString("short", "abc", 4),
+		logger.WithTruncatedString("exact", "abcd", 4),
+	)
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	for key, want := range map[string]string{
+		"body":  "あ…(truncated 6 bytes)",
+		"short": "abc",
+		"exact": "abcd",
+	} {
+		if got := entries[0].Attrs[key]; got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestMaxStringBytes(t *testing.T) {
+	l, capture := logtest.NewCapture(logger.WithMaxStringBytes(8))
+	long := strings.Repeat("x", 10)
+
+	l.Info(context.Background(), long, logger.WithAttrs(
+		slog.String("body", long),
+		slog.Group("req", slog.String("body", long)),
+		slog.String("short", "abc"),
+	), logger.WithTruncatedString("once", long, 4))
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Message != long {
+		t.Errorf("message = %q, want the one not truncated", e.Message)
+	}
+	want := "xxxxxxxx…(truncated 2 bytes)"
+	if req, _ := e.Attrs["req"].(map[string]any); e.Attrs["body"] != want || req["body"] != want {
+		t.Errorf("body = %q, req = %v, want %q", e.Attrs["body"], e.Attrs["req"], want)
+	}
+	if e.Attrs["short"] != "abc" || e.Attrs["once"] != "xxxx…(truncated 6 bytes)" {
+		t.Errorf("short = %q, once = %q, want them as they are", e.Attrs["short"], e.Attrs["once"])
+	}
+}
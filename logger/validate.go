@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// stackFramePattern matches a frame of the stack trace in the format of runtime/debug.Stack,
+// which Error Reporting parses as a Go stack trace.
+var stackFramePattern = regexp.MustCompile(`(?m)^\S.*\(.*\)\n\t\S+:\d+`)
+
+// ValidateErrorReport checks that the JSON line written by the logger is ingested by Error Reporting
+// as a ReportedErrorEvent, e.g. to run it over the output captured in the integration tests.
+// It requires:
+//
+//   - "@type" to be the type of ReportedErrorEvent.
+//   - "message" to be a non-empty string.
+//   - "stack_trace" to be a Go stack trace, or "context.reportLocation" to have the filePath, the lineNumber and the functionName.
+//   - "severity" to be ERROR or above.
+//   - "serviceContext", if any, to have the service.
+//
+// The returned error lists all the missing or invalid fields. Note that the entries reported by WithErrorReport
+// below ERROR are rejected, and the lines written with WithMessageKey lack "message".
+func ValidateErrorReport(jsonLine []byte) error {
+	var entry map[string]any
+	if err := json.Unmarshal(jsonLine, &entry); err != nil {
+		return fmt.Errorf("invalid error report: not a JSON object: %w", err)
+	}
+
+	var errs []error
+	switch typ, ok := entry[KeyType]; {
+	case !ok:
+		errs = append(errs, fmt.Errorf("%q is missing", KeyType))
+	case typ != logAttrReporting.Value.String():
+		errs = append(errs, fmt.Errorf("%q must be %q, got %v", KeyType, logAttrReporting.Value.String(), typ))
+	}
+	if msg, _ := entry[KeyMessage].(string); msg == "" {
+		errs = append(errs, fmt.Errorf("%q must be a non-empty string", KeyMessage))
+	}
+	if err := validateReportLocation(entry); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateReportSeverity(entry); err != nil {
+		errs = append(errs, err)
+	}
+	if sc, ok := entry[KeyServiceContext]; ok {
+		m, _ := sc.(map[string]any)
+		if service, _ := m["service"].(string); service == "" {
+			errs = append(errs, fmt.Errorf("%q must have a non-empty service", KeyServiceContext))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid error report: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func validateReportLocation(entry map[string]any) error {
+	if stack, ok := entry[KeyStackTrace]; ok {
+		s, _ := stack.(string)
+		if !stackFramePattern.MatchString(s) {
+			return fmt.Errorf("%q must be a Go stack trace with at least one frame", KeyStackTrace)
+		}
+		return nil
+	}
+
+	ctx, _ := entry[KeyContext].(map[string]any)
+	loc, ok := ctx["reportLocation"].(map[string]any)
+	if !ok {
+		return fmt.Errorf("either %q or %q must be set", KeyStackTrace, KeyContext+".reportLocation")
+	}
+	var errs []error
+	for _, key := range []string{"filePath", "functionName"} {
+		if v, _ := loc[key].(string); v == "" {
+			errs = append(errs, fmt.Errorf("%q must be a non-empty string", KeyContext+".reportLocation."+key))
+		}
+	}
+	if line, _ := loc["lineNumber"].(float64); line <= 0 {
+		errs = append(errs, fmt.Errorf("%q must be a positive number", KeyContext+".reportLocation.lineNumber"))
+	}
+	return errors.Join(errs...)
+}
+
+func validateReportSeverity(entry map[string]any) error {
+	s, ok := entry[KeySeverity].(string)
+	if !ok {
+		return fmt.Errorf("%q is missing", KeySeverity)
+	}
+	level, err := ParseLevel(s)
+	if err != nil {
+		return fmt.Errorf("%q: %w", KeySeverity, err)
+	}
+	if level < LevelError {
+		return fmt.Errorf("%q must be ERROR or above, got %s", KeySeverity, s)
+	}
+	return nil
+}
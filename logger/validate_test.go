@@ -0,0 +1,99 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+)
+
+// reportLine returns the JSON line written by log.
+func reportLine(t *testing.T, log func(ctx context.Context, l *logger.Logger)) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	l := logger.New(&buf, "p", logger.LevelDefault, logger.WithServiceContext("api", "v1"))
+	log(context.Background(), l)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+func TestValidateErrorReport(t *testing.T) {
+	tests := map[string]func(ctx context.Context, l *logger.Logger){
+		"Error":    func(ctx context.Context, l *logger.Logger) { l.Error(ctx, errors.New("failed")) },
+		"Critical": func(ctx context.Context, l *logger.Logger) { l.Critical(ctx, errors.New("failed")) },
+		"Recover": func(ctx context.Context, l *logger.Logger) {
+			defer l.Recover(ctx)
+			panic("boom")
+		},
+		"LogPanic": func(ctx context.Context, l *logger.Logger) { l.LogPanic(ctx, "boom") },
+		"reportLocation": func(ctx context.Context, l *logger.Logger) {
+			l.Error(ctx, errors.New("failed"), logger.WithStackTrace(false))
+		},
+	}
+	for name, log := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := reportLine(t, log)
+			if err := logger.ValidateErrorReport(line); err != nil {
+				t.Errorf("ValidateErrorReport(%s) = %v, want nil", line, err)
+			}
+		})
+	}
+}
+
+func TestValidateErrorReportInvalid(t *testing.T) {
+	errorLine := func(ctx context.Context, l *logger.Logger) { l.Error(ctx, errors.New("failed")) }
+	locationLine := func(ctx context.Context, l *logger.Logger) {
+		l.Error(ctx, errors.New("failed"), logger.WithStackTrace(false))
+	}
+	tests := []struct {
+		name   string
+		log    func(ctx context.Context, l *logger.Logger)
+		modify func(entry map[string]any)
+		field  string
+	}{
+		{"wrong @type", errorLine, func(e map[string]any) { e[logger.KeyType] = "type.googleapis.com/other" }, logger.KeyType},
+		{"missing @type", errorLine, func(e map[string]any) { delete(e, logger.KeyType) }, logger.KeyType},
+		{"missing service", errorLine, func(e map[string]any) { e[logger.KeyServiceContext] = map[string]any{"version": "v1"} }, logger.KeyServiceContext},
+		{"missing message", errorLine, func(e map[string]any) { delete(e, logger.KeyMessage) }, logger.KeyMessage},
+		{"neither stack nor reportLocation", locationLine, func(e map[string]any) { delete(e, logger.KeyContext) }, logger.KeyStackTrace},
+		{"invalid stack", errorLine, func(e map[string]any) { e[logger.KeyStackTrace] = "not a stack" }, logger.KeyStackTrace},
+		{"invalid reportLocation", locationLine, func(e map[string]any) {
+			e[logger.KeyContext] = map[string]any{"reportLocation": map[string]any{"filePath": "main.go", "lineNumber": 0}}
+		}, "reportLocation.lineNumber"},
+		{"sub-ERROR severity", errorLine, func(e map[string]any) { e[logger.KeySeverity] = "WARNING" }, logger.KeySeverity},
+		{"missing severity", errorLine, func(e map[string]any) { delete(e, logger.KeySeverity) }, logger.KeySeverity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entry map[string]any
+			if err := json.Unmarshal(reportLine(t, tt.log), &entry); err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(entry)
+			line, err := json.Marshal(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = logger.ValidateErrorReport(line)
+			if err == nil || !strings.Contains(err.Error(), tt.field) {
+				t.Errorf("ValidateErrorReport(%s) = %v, want the error naming %q", line, err, tt.field)
+			}
+		})
+	}
+
+	if err := logger.ValidateErrorReport([]byte("not JSON")); err == nil {
+		t.Error("ValidateErrorReport(not JSON) = nil, want the error")
+	}
+}
+
+func TestValidateErrorReportBelowError(t *testing.T) {
+	line := reportLine(t, func(ctx context.Context, l *logger.Logger) {
+		l.Warn(ctx, "degraded", logger.WithErrorReport(true))
+	})
+	if err := logger.ValidateErrorReport(line); err == nil || !strings.Contains(err.Error(), logger.KeySeverity) {
+		t.Errorf("ValidateErrorReport(%s) = %v, want the error of the severity below ERROR", line, err)
+	}
+}
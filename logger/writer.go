@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// SetWriter swaps the io.Writer of the logger given to New, e.g. to reopen the log file rotated externally.
+// It is safe to call concurrently with logging: each entry is written entirely to either the old or the new writer.
+// The old writer is not closed nor flushed. SetWriter has no effect on the loggers not writing to an io.Writer given to New,
+// such as those of NewWithClient, WithCloudLogging, WithSplitWriters and WithHandler. The change is shared by the loggers derived by With.
+func (l *Logger) SetWriter(w io.Writer) {
+	if l.writer != nil {
+		l.writer.set(w)
+	}
+}
+
+// swapWriter is the io.Writer whose destination can be swapped at runtime.
+type swapWriter struct {
+	w atomic.Pointer[writerHolder]
+}
+
+type writerHolder struct {
+	io.Writer
+}
+
+func newSwapWriter(w io.Writer) *swapWriter {
+	sw := &swapWriter{}
+	sw.set(w)
+	return sw
+}
+
+func (sw *swapWriter) set(w io.Writer) {
+	sw.w.Store(&writerHolder{w})
+}
+
+func (sw *swapWriter) current() io.Writer {
+	return sw.w.Load().Writer
+}
+
+func (sw *swapWriter) Write(p []byte) (int, error) {
+	return sw.current().Write(p)
+}
+
+// Flush flushes the current writer if it has a Flush method.
+func (sw *swapWriter) Flush() error {
+	if f, ok := sw.current().(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package logger_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ebi-yade/osuite/logger"
+	"github.com/ebi-yade/osuite/logger/logtest"
+)
+
+func TestSetWriter(t *testing.T) {
+	old, rotated := &logtest.Capture{}, &logtest.Capture{}
+	l := logger.New(old, "p", logger.LevelInfo)
+	ctx := context.Background()
+	const goroutines, writes = 50, 20
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				l.Info(ctx, "hello")
+			}
+		}()
+	}
+	l.SetWriter(rotated)
+	wg.Wait()
+	l.Info(ctx, "after rotation")
+
+	for name, c := range map[string]*logtest.Capture{"old": old, "rotated": rotated} {
+		if err := c.Err(); err != nil {
+			t.Errorf("%s has a broken line: %v", name, err)
+		}
+	}
+	if got := len(old.Entries()) + len(rotated.Entries()); got != goroutines*writes+1 {
+		t.Errorf("got %d entries in total, want %d", got, goroutines*writes+1)
+	}
+	if entries := rotated.Entries(); len(entries) == 0 || entries[len(entries)-1].Message != "after rotation" {
+		t.Errorf("the entry after SetWriter is not written to the new writer")
+	}
+}